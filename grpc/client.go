@@ -3,7 +3,6 @@ package grpc
 import (
 	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"log/slog"
 
@@ -11,20 +10,35 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/metadata"
 )
 
-func NewClient(log *logger.Logger, cfg *ClientConfig, clientTLSConfig *tls.Config) (*grpc.ClientConn, error) {
+func NewClient(log *logger.Logger, cfg *ClientConfig, clientTLSConfig *tls.Config, opts ...ClientOption) (*grpc.ClientConn, error) {
+	o := newClientOptions(opts)
 	clientCreds := credentials.NewTLS(clientTLSConfig)
+	m := o.registry.GRPCClientMetrics()
 
-	conn, err := grpc.NewClient(
-		fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+	unaryInterceptors := append([]grpc.UnaryClientInterceptor{
+		contextInterceptor(),
+		clientLogInterceptor(log),
+		clientMetricInterceptor(m),
+	}, o.unaryClientInterceptors...)
+
+	streamInterceptors := append([]grpc.StreamClientInterceptor{
+		contextStreamInterceptor(),
+		clientLogStreamInterceptor(log),
+		clientMetricStreamInterceptor(m),
+	}, o.streamClientInterceptors...)
+
+	dialOpts := append([]grpc.DialOption{
 		grpc.WithTransportCredentials(clientCreds),
-		grpc.WithUnaryInterceptor(contextInterceptor(log)),
-		grpc.WithUnaryInterceptor(clientLogInterceptor(log)),
-	)
+		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+		grpc.WithChainStreamInterceptor(streamInterceptors...),
+	}, o.dialOptions...)
+
+	conn, err := grpc.NewClient(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port), dialOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -32,26 +46,25 @@ func NewClient(log *logger.Logger, cfg *ClientConfig, clientTLSConfig *tls.Confi
 	return conn, nil
 }
 
-func contextInterceptor(log *logger.Logger) grpc.UnaryClientInterceptor {
-	log = log.With(slog.String("component", "grpc client"))
-
-	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		ginCtx, ok := ctx.(*gin.Context)
-		if !ok {
-			err := errors.New("failed to get request context")
-			log.Error("failed to get request context", err, slog.String("method", method))
-			return err
-		}
+// requestContext unwraps *gin.Context passed by HTTP handlers, its Value does not fall back
+// to the request context, so the request span and deadline would be lost. Other contexts are used as is.
+func requestContext(ctx context.Context) context.Context {
+	if ginCtx, ok := ctx.(*gin.Context); ok && ginCtx.Request != nil {
+		return ginCtx.Request.Context()
+	}
 
-		reqCtx := ginCtx.Request.Context()
+	return ctx
+}
 
-		err := invoker(reqCtx, method, req, reply, cc, opts...)
-		if err != nil {
-			log.Error("failed to invoke", err, slog.String("method", method), slog.String("interceptor", "context interceptor"))
-			return err
-		}
+func contextInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(requestContext(ctx), method, req, reply, cc, opts...)
+	}
+}
 
-		return nil
+func contextStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(requestContext(ctx), desc, cc, method, opts...)
 	}
 }
 
@@ -59,22 +72,23 @@ func clientLogInterceptor(log *logger.Logger) grpc.UnaryClientInterceptor {
 	log = log.With(slog.String("component", "grpc client"))
 	tracer := otel.GetTracerProvider().Tracer("grpc client")
 
-	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		log.Info("[Restaurant Service Interceptor]", slog.String("method", method))
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+		defer func() {
+			endSpan(span, err)
+		}()
 
-		ctx, span := tracer.Start(ctx, method)
-		defer span.End()
+		log.InfoContext(ctx, "[Restaurant Service Interceptor]", slog.String("method", method))
 
-		traceId := fmt.Sprintf("%s", span.SpanContext().TraceID())
-		ctx = metadata.AppendToOutgoingContext(ctx, "x-trace-id", traceId)
+		ctx = injectContext(ctx)
 
-		err := invoker(ctx, method, req, reply, cc, opts...)
+		err = invoker(ctx, method, req, reply, cc, opts...)
 		if err != nil {
-			log.Error("failed to invoke", err, slog.String("method", method), slog.String("x-trace-id", traceId), slog.String("interceptor", "log/trace interceptor"))
+			log.ErrorContext(ctx, "failed to invoke", err, slog.String("method", method), slog.String("interceptor", "log/trace interceptor"))
 			return err
 		}
 
-		log.Info("got reply", slog.Any("reply", reply))
+		log.InfoContext(ctx, "got reply", slog.Any("reply", reply))
 
 		return nil
 	}
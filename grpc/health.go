@@ -0,0 +1,201 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/logger"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+)
+
+// Checker checks a dependency of the service is available
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to Checker, e.g. CheckerFunc(postgres.Ping)
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type namedChecker struct {
+	service string
+	name    string
+	checker Checker
+}
+
+// HealthServer implements grpc.health.v1.Health with status driven by checkers.
+// A service is SERVING when all its checkers pass, the server status (empty service name)
+// is SERVING when every checker passes. Services without checkers are not reported.
+type HealthServer struct {
+	*health.Server
+
+	log      *logger.Logger
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.Mutex
+	checkers []namedChecker
+	failing  map[int]bool
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+}
+
+type HealthOption func(*HealthServer)
+
+// HealthCheckInterval sets how often checkers run, 10 seconds by default
+func HealthCheckInterval(interval time.Duration) HealthOption {
+	return func(h *HealthServer) {
+		h.interval = interval
+	}
+}
+
+// HealthCheckTimeout sets the deadline of a single run of all checkers, 5 seconds by default
+func HealthCheckTimeout(timeout time.Duration) HealthOption {
+	return func(h *HealthServer) {
+		h.timeout = timeout
+	}
+}
+
+func NewHealthServer(log *logger.Logger, opts ...HealthOption) *HealthServer {
+	h := &HealthServer{
+		Server:   health.NewServer(),
+		log:      log.With(slog.String("component", "grpc health")),
+		interval: defaultHealthCheckInterval,
+		timeout:  defaultHealthCheckTimeout,
+		failing:  make(map[int]bool),
+		stop:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// AddChecker adds checker of the service, empty service affects only the server status.
+// The service is NOT_SERVING until the checker passes.
+func (h *HealthServer) AddChecker(service, name string, checker Checker) {
+	h.mu.Lock()
+	h.checkers = append(h.checkers, namedChecker{service: service, name: name, checker: checker})
+	h.mu.Unlock()
+
+	h.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	h.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Start runs checkers immediately and then every interval until Shutdown
+func (h *HealthServer) Start() {
+	h.startOnce.Do(func() {
+		go h.run()
+	})
+}
+
+// Shutdown stops the checks and sets every service NOT_SERVING, so load balancers stop
+// sending new requests. GracefulStop calls it before stopping the server.
+func (h *HealthServer) Shutdown() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+
+	h.Server.Shutdown()
+}
+
+func (h *HealthServer) run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.RunChecks()
+
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunChecks runs all checkers concurrently and updates statuses of the services
+func (h *HealthServer) RunChecks() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	h.mu.Lock()
+	checkers := append([]namedChecker(nil), h.checkers...)
+	h.mu.Unlock()
+
+	errs := make([]error, len(checkers))
+
+	var wg sync.WaitGroup
+
+	for i, c := range checkers {
+		wg.Add(1)
+
+		go func(i int, c namedChecker) {
+			defer wg.Done()
+			errs[i] = c.checker.Check(ctx)
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	serving := map[string]bool{"": true}
+
+	for i, c := range checkers {
+		if _, ok := serving[c.service]; !ok {
+			serving[c.service] = true
+		}
+
+		if errs[i] != nil {
+			serving[c.service] = false
+			serving[""] = false
+		}
+
+		h.report(i, c, errs[i])
+	}
+
+	for service, ok := range serving {
+		if ok {
+			h.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+		} else {
+			h.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	}
+}
+
+// report logs when the i-th checker starts or stops failing
+func (h *HealthServer) report(i int, c namedChecker, err error) {
+	h.mu.Lock()
+	wasFailing := h.failing[i]
+	h.failing[i] = err != nil
+	h.mu.Unlock()
+
+	switch {
+	case err != nil && !wasFailing:
+		h.log.Warn("health check failed",
+			slog.String("service", c.service),
+			slog.String("checker", c.name),
+			slog.String("error", err.Error()),
+		)
+	case err == nil && wasFailing:
+		h.log.Info("health check recovered",
+			slog.String("service", c.service),
+			slog.String("checker", c.name),
+		)
+	}
+}
@@ -0,0 +1,231 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	serving    = healthpb.HealthCheckResponse_SERVING
+	notServing = healthpb.HealthCheckResponse_NOT_SERVING
+)
+
+var (
+	passing = CheckerFunc(func(context.Context) error { return nil })
+	failing = CheckerFunc(func(context.Context) error { return errors.New("connection refused") })
+)
+
+func assertHealth(t *testing.T, h *HealthServer, service string, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+
+	resp, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("check %q: %v", service, err)
+	}
+
+	if resp.GetStatus() != want {
+		t.Errorf("status of %q = %s, want %s", service, resp.GetStatus(), want)
+	}
+}
+
+func TestHealthNotServingBeforeFirstCheck(t *testing.T) {
+	h := NewHealthServer(logger.New())
+	h.AddChecker("orders", "postgres", passing)
+
+	assertHealth(t, h, "orders", notServing)
+	assertHealth(t, h, "", notServing)
+
+	h.RunChecks()
+
+	assertHealth(t, h, "orders", serving)
+	assertHealth(t, h, "", serving)
+}
+
+func TestHealthRunChecks(t *testing.T) {
+	type checker struct {
+		service string
+		checker Checker
+	}
+
+	tests := []struct {
+		name     string
+		checkers []checker
+		want     map[string]healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name:     "no checkers",
+			checkers: nil,
+			want:     map[string]healthpb.HealthCheckResponse_ServingStatus{"": serving},
+		},
+		{
+			name:     "all pass",
+			checkers: []checker{{"orders", passing}, {"orders", passing}, {"menu", passing}},
+			want:     map[string]healthpb.HealthCheckResponse_ServingStatus{"": serving, "orders": serving, "menu": serving},
+		},
+		{
+			name:     "one of service fails",
+			checkers: []checker{{"orders", passing}, {"orders", failing}, {"menu", passing}},
+			want:     map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing, "orders": notServing, "menu": serving},
+		},
+		{
+			name:     "server checker fails",
+			checkers: []checker{{"", failing}, {"menu", passing}},
+			want:     map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing, "menu": serving},
+		},
+		{
+			name:     "server checker passes",
+			checkers: []checker{{"", passing}, {"menu", failing}},
+			want:     map[string]healthpb.HealthCheckResponse_ServingStatus{"": notServing, "menu": notServing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthServer(logger.New())
+			for i, c := range tt.checkers {
+				h.AddChecker(c.service, string(rune('a'+i)), c.checker)
+			}
+
+			h.RunChecks()
+
+			for service, want := range tt.want {
+				assertHealth(t, h, service, want)
+			}
+		})
+	}
+}
+
+func TestHealthRecovers(t *testing.T) {
+	var err error
+
+	h := NewHealthServer(logger.New())
+	h.AddChecker("orders", "postgres", CheckerFunc(func(context.Context) error { return err }))
+
+	err = errors.New("connection refused")
+	h.RunChecks()
+	assertHealth(t, h, "orders", notServing)
+
+	err = nil
+	h.RunChecks()
+	assertHealth(t, h, "orders", serving)
+	assertHealth(t, h, "", serving)
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	h := NewHealthServer(logger.New(), HealthCheckTimeout(10*time.Millisecond))
+	h.AddChecker("orders", "slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	h.RunChecks()
+
+	assertHealth(t, h, "orders", notServing)
+}
+
+func TestHealthStartRunsChecks(t *testing.T) {
+	h := NewHealthServer(logger.New(), HealthCheckInterval(time.Hour))
+	h.AddChecker("orders", "postgres", passing)
+
+	h.Start()
+	t.Cleanup(h.Shutdown)
+
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		resp, err := h.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "orders"})
+		if err == nil && resp.GetStatus() == serving {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("orders is not serving after Start")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHealthShutdown(t *testing.T) {
+	h := NewHealthServer(logger.New())
+	h.AddChecker("orders", "postgres", passing)
+	h.RunChecks()
+
+	h.Shutdown()
+
+	assertHealth(t, h, "orders", notServing)
+	assertHealth(t, h, "", notServing)
+
+	// checks after shutdown must not flip the status back
+	h.RunChecks()
+
+	assertHealth(t, h, "orders", notServing)
+}
+
+func TestGracefulStopWatch(t *testing.T) {
+	h := NewHealthServer(logger.New())
+	h.AddChecker("orders", "postgres", passing)
+	h.RunChecks()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, h)
+
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	watch, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{Service: "orders"})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+
+	if resp, err := watch.Recv(); err != nil || resp.GetStatus() != serving {
+		t.Fatalf("first status = %v, %v, want SERVING", resp.GetStatus(), err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		// the Watch stream never ends by itself, so the server is stopped when ctx is done
+		GracefulStop(ctx, srv, h)
+		close(stopped)
+	}()
+
+	if resp, err := watch.Recv(); err != nil || resp.GetStatus() != notServing {
+		t.Fatalf("status after stop = %v, %v, want NOT_SERVING", resp.GetStatus(), err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("GracefulStop did not return")
+	}
+}
@@ -0,0 +1,224 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	unary        = "unary"
+	clientStream = "client_stream"
+	serverStream = "server_stream"
+	bidiStream   = "bidi_stream"
+)
+
+// rpcLabels holds labels of a single RPC: type, service and method
+type rpcLabels [3]string
+
+func newRPCLabels(rpcType, fullMethod string) rpcLabels {
+	service, method := splitMethodName(fullMethod)
+	return rpcLabels{rpcType, service, method}
+}
+
+// splitMethodName splits "/package.Service/Method" into service and method
+func splitMethodName(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+
+	return "unknown", "unknown"
+}
+
+func streamType(clientStreams, serverStreams bool) string {
+	switch {
+	case clientStreams && serverStreams:
+		return bidiStream
+	case clientStreams:
+		return clientStream
+	case serverStreams:
+		return serverStream
+	default:
+		return unary
+	}
+}
+
+// rpcReporter records metrics of a single RPC
+type rpcReporter struct {
+	m      *metrics.GRPCMetrics
+	labels rpcLabels
+	start  time.Time
+	once   sync.Once
+}
+
+func newRPCReporter(m *metrics.GRPCMetrics, labels rpcLabels) *rpcReporter {
+	m.StartedTotal.WithLabelValues(labels[:]...).Inc()
+
+	return &rpcReporter{
+		m:      m,
+		labels: labels,
+		start:  time.Now(),
+	}
+}
+
+func (r *rpcReporter) received() {
+	r.m.MsgReceivedTotal.WithLabelValues(r.labels[:]...).Inc()
+}
+
+func (r *rpcReporter) sent() {
+	r.m.MsgSentTotal.WithLabelValues(r.labels[:]...).Inc()
+}
+
+// handled records the status code and duration, only the first call counts
+func (r *rpcReporter) handled(err error) {
+	r.once.Do(func() {
+		r.m.HandledTotal.WithLabelValues(r.labels[0], r.labels[1], r.labels[2], status.Code(err).String()).Inc()
+		r.m.HandlingSeconds.WithLabelValues(r.labels[:]...).Observe(time.Since(r.start).Seconds())
+	})
+}
+
+func serverMetricInterceptor(m *metrics.GRPCMetrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		r := newRPCReporter(m, newRPCLabels(unary, info.FullMethod))
+		r.received()
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			r.sent()
+		}
+
+		r.handled(err)
+
+		return resp, err
+	}
+}
+
+func serverMetricStreamInterceptor(m *metrics.GRPCMetrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		r := newRPCReporter(m, newRPCLabels(streamType(info.IsClientStream, info.IsServerStream), info.FullMethod))
+
+		err := handler(srv, &monitoredServerStream{ServerStream: ss, r: r})
+
+		r.handled(err)
+
+		return err
+	}
+}
+
+type monitoredServerStream struct {
+	grpc.ServerStream
+	r *rpcReporter
+}
+
+func (s *monitoredServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.r.sent()
+	}
+
+	return err
+}
+
+func (s *monitoredServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.r.received()
+	}
+
+	return err
+}
+
+func clientMetricInterceptor(m *metrics.GRPCMetrics) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		r := newRPCReporter(m, newRPCLabels(unary, method))
+		r.sent()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil {
+			r.received()
+		}
+
+		r.handled(err)
+
+		return err
+	}
+}
+
+func clientMetricStreamInterceptor(m *metrics.GRPCMetrics) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		r := newRPCReporter(m, newRPCLabels(streamType(desc.ClientStreams, desc.ServerStreams), method))
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			r.handled(err)
+			return nil, err
+		}
+
+		stream := &monitoredClientStream{
+			ClientStream:  cs,
+			r:             r,
+			serverStreams: desc.ServerStreams,
+			done:          make(chan struct{}),
+		}
+
+		finishOnDone(ctx, stream.done, stream.handled)
+
+		return stream, nil
+	}
+}
+
+// monitoredClientStream reports the RPC as handled when receiving ends or the context of the stream is done,
+// io.EOF means the stream finished successfully
+type monitoredClientStream struct {
+	grpc.ClientStream
+	r             *rpcReporter
+	serverStreams bool
+	once          sync.Once
+	done          chan struct{}
+}
+
+func (s *monitoredClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.r.sent()
+	}
+
+	return err
+}
+
+func (s *monitoredClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	switch {
+	case err == nil:
+		s.r.received()
+
+		// the single reply of a client streaming RPC ends it
+		if !s.serverStreams {
+			s.handled(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.handled(nil)
+	default:
+		s.handled(err)
+	}
+
+	return err
+}
+
+func (s *monitoredClientStream) handled(err error) {
+	s.once.Do(func() {
+		s.r.handled(err)
+		close(s.done)
+	})
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"google.golang.org/grpc"
+)
+
+type serverOptions struct {
+	registry *metrics.Registry
+	health   *HealthServer
+}
+
+type clientOptions struct {
+	registry *metrics.Registry
+
+	unaryClientInterceptors  []grpc.UnaryClientInterceptor
+	streamClientInterceptors []grpc.StreamClientInterceptor
+	dialOptions              []grpc.DialOption
+}
+
+// ServerOption configures NewServer
+type ServerOption interface {
+	applyServer(*serverOptions)
+}
+
+// ClientOption configures NewClient
+type ClientOption interface {
+	applyClient(*clientOptions)
+}
+
+// Option configures both NewServer and NewClient
+type Option interface {
+	ServerOption
+	ClientOption
+}
+
+type serverOptionFunc func(*serverOptions)
+
+func (f serverOptionFunc) applyServer(o *serverOptions) {
+	f(o)
+}
+
+type clientOptionFunc func(*clientOptions)
+
+func (f clientOptionFunc) applyClient(o *clientOptions) {
+	f(o)
+}
+
+type registryOption struct {
+	registry *metrics.Registry
+}
+
+func (r registryOption) applyServer(o *serverOptions) {
+	o.registry = r.registry
+}
+
+func (r registryOption) applyClient(o *clientOptions) {
+	o.registry = r.registry
+}
+
+func newServerOptions(opts []ServerOption) *serverOptions {
+	o := &serverOptions{
+		registry: metrics.Default(),
+	}
+
+	for _, opt := range opts {
+		opt.applyServer(o)
+	}
+
+	return o
+}
+
+func newClientOptions(opts []ClientOption) *clientOptions {
+	o := &clientOptions{
+		registry: metrics.Default(),
+	}
+
+	for _, opt := range opts {
+		opt.applyClient(o)
+	}
+
+	return o
+}
+
+// MetricsRegistry sets registry for the gRPC metrics, metrics.Default by default
+func MetricsRegistry(reg *metrics.Registry) Option {
+	return registryOption{registry: reg}
+}
+
+// Health registers the health service on the server and starts its checks. Stop the server with
+// GracefulStop, so the service reports NOT_SERVING first. No health service is registered by default.
+func Health(h *HealthServer) ServerOption {
+	return serverOptionFunc(func(o *serverOptions) {
+		o.health = h
+	})
+}
+
+// UnaryClientInterceptors appends interceptors to the client chain, they run after the platform ones
+func UnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) ClientOption {
+	return clientOptionFunc(func(o *clientOptions) {
+		o.unaryClientInterceptors = append(o.unaryClientInterceptors, interceptors...)
+	})
+}
+
+// StreamClientInterceptors appends stream interceptors to the client chain, they run after the platform ones
+func StreamClientInterceptors(interceptors ...grpc.StreamClientInterceptor) ClientOption {
+	return clientOptionFunc(func(o *clientOptions) {
+		o.streamClientInterceptors = append(o.streamClientInterceptors, interceptors...)
+	})
+}
+
+// DialOptions appends options used to create the client connection, they override the platform ones
+func DialOptions(opts ...grpc.DialOption) ClientOption {
+	return clientOptionFunc(func(o *clientOptions) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	})
+}
@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// legacyTraceIDKey is the metadata key of the trace id sent before W3C trace context was supported.
+// It is still sent, so servers not upgraded yet keep joining the trace of the caller.
+const legacyTraceIDKey = "x-trace-id"
+
+// defaultPropagator is used until the global propagator is configured, e.g. by tracing.New
+var defaultPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// propagator returns the global propagator, W3C trace context and baggage when it is not configured
+func propagator() propagation.TextMapPropagator {
+	p := otel.GetTextMapPropagator()
+	if len(p.Fields()) == 0 {
+		return defaultPropagator
+	}
+
+	return p
+}
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// injectContext writes span context and baggage of ctx into outgoing metadata
+func injectContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+
+	propagator().Inject(ctx, metadataCarrier(md))
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		md.Set(legacyTraceIDKey, sc.TraceID().String())
+	}
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// extractContext reads remote span context and baggage from incoming metadata.
+// Standard traceparent is accepted even when the global propagator uses another format,
+// x-trace-id of older clients is used when neither yields a span context.
+// Missing or malformed metadata is ignored, so the server span becomes a new root.
+func extractContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	carrier := metadataCarrier(md)
+
+	extracted := propagator().Extract(ctx, carrier)
+	if trace.SpanContextFromContext(extracted).IsValid() {
+		return extracted
+	}
+
+	extracted = propagation.TraceContext{}.Extract(extracted, carrier)
+	if trace.SpanContextFromContext(extracted).IsValid() {
+		return extracted
+	}
+
+	return extractLegacyTraceID(extracted, carrier)
+}
+
+// extractLegacyTraceID sets remote span context holding only the trace id,
+// spans started from it join the trace of the caller
+func extractLegacyTraceID(ctx context.Context, carrier metadataCarrier) context.Context {
+	traceID, err := trace.TraceIDFromHex(carrier.Get(legacyTraceIDKey))
+	if err != nil {
+		return ctx
+	}
+
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		Remote:  true,
+	}))
+}
@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID      = "00f067aa0ba902b7"
+	testTraceParent = "00-" + testTraceID + "-" + testSpanID + "-01"
+)
+
+func TestExtractContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		md      metadata.MD
+		traceID string
+		spanID  string
+		sampled bool
+	}{
+		{name: "no metadata"},
+		{name: "empty metadata", md: metadata.MD{}},
+		{name: "malformed traceparent", md: metadata.Pairs("traceparent", "00-xyz-"+testSpanID+"-01")},
+		{name: "malformed x-trace-id", md: metadata.Pairs(legacyTraceIDKey, "not-a-trace-id")},
+		{name: "zero x-trace-id", md: metadata.Pairs(legacyTraceIDKey, "00000000000000000000000000000000")},
+		{
+			name:    "traceparent",
+			md:      metadata.Pairs("traceparent", testTraceParent),
+			traceID: testTraceID,
+			spanID:  testSpanID,
+			sampled: true,
+		},
+		{
+			name:    "traceparent wins over x-trace-id",
+			md:      metadata.Pairs("traceparent", testTraceParent, legacyTraceIDKey, "0af7651916cd43dd8448eb211c80319c"),
+			traceID: testTraceID,
+			spanID:  testSpanID,
+			sampled: true,
+		},
+		{
+			name:    "x-trace-id",
+			md:      metadata.Pairs(legacyTraceIDKey, testTraceID),
+			traceID: testTraceID,
+		},
+		{
+			name:    "x-trace-id with malformed traceparent",
+			md:      metadata.Pairs("traceparent", "garbage", legacyTraceIDKey, testTraceID),
+			traceID: testTraceID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			sc := trace.SpanContextFromContext(extractContext(ctx))
+
+			if tt.traceID == "" {
+				if sc.TraceID().IsValid() {
+					t.Fatalf("trace id = %s, want none", sc.TraceID())
+				}
+
+				return
+			}
+
+			if got := sc.TraceID().String(); got != tt.traceID {
+				t.Errorf("trace id = %s, want %s", got, tt.traceID)
+			}
+
+			if tt.spanID != "" && sc.SpanID().String() != tt.spanID {
+				t.Errorf("span id = %s, want %s", sc.SpanID(), tt.spanID)
+			}
+
+			if sc.IsSampled() != tt.sampled {
+				t.Errorf("sampled = %v, want %v", sc.IsSampled(), tt.sampled)
+			}
+
+			if !sc.IsRemote() {
+				t.Error("span context is not remote")
+			}
+		})
+	}
+}
+
+func TestInjectContextSendsLegacyTraceID(t *testing.T) {
+	tid, _ := trace.TraceIDFromHex(testTraceID)
+	sid, _ := trace.SpanIDFromHex(testSpanID)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid, TraceFlags: trace.FlagsSampled})
+
+	ctx := injectContext(trace.ContextWithSpanContext(context.Background(), sc))
+	md, _ := metadata.FromOutgoingContext(ctx)
+
+	if got := md.Get("traceparent"); len(got) != 1 || got[0] != testTraceParent {
+		t.Errorf("traceparent = %v, want %s", got, testTraceParent)
+	}
+
+	if got := md.Get(legacyTraceIDKey); len(got) != 1 || got[0] != testTraceID {
+		t.Errorf("%s = %v, want %s", legacyTraceIDKey, got, testTraceID)
+	}
+}
@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/TakeAway-Inc/platform/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serverRecoveryInterceptor turns a panic of the handler into codes.Internal error.
+// It must be the last interceptor in the chain to see the server span in the context.
+func serverRecoveryInterceptor(log *logger.Logger, panics *prometheus.CounterVec) grpc.UnaryServerInterceptor {
+	log = log.With(slog.String("component", "grpc server"))
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(ctx, log, panics, newRPCLabels(unary, info.FullMethod), r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func serverRecoveryStreamInterceptor(log *logger.Logger, panics *prometheus.CounterVec) grpc.StreamServerInterceptor {
+	log = log.With(slog.String("component", "grpc server"))
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				labels := newRPCLabels(streamType(info.IsClientStream, info.IsServerStream), info.FullMethod)
+				err = recoverPanic(ss.Context(), log, panics, labels, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// recoverPanic reports the recovered panic and returns error sent to the client,
+// the panic value is not exposed to the client
+func recoverPanic(ctx context.Context, log *logger.Logger, panics *prometheus.CounterVec, labels rpcLabels, r any) error {
+	stack := string(debug.Stack())
+	err := fmt.Errorf("panic: %v", r)
+
+	panics.WithLabelValues(labels[:]...).Inc()
+
+	log.ErrorContext(ctx, "recovered from panic", err,
+		slog.String("method", "/"+labels[1]+"/"+labels[2]),
+		slog.String("stack", stack),
+	)
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(semconv.ExceptionStacktrace(stack)))
+	span.SetStatus(codes.Error, err.Error())
+
+	return status.Error(grpccodes.Internal, "internal error")
+}
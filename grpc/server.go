@@ -6,70 +6,86 @@ import (
 	"log/slog"
 
 	"github.com/TakeAway-Inc/platform/logger"
-	"github.com/TakeAway-Inc/platform/metrics"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/metadata"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-func NewServer(log *logger.Logger, clientTLSConfig *tls.Config) *grpc.Server {
+func NewServer(log *logger.Logger, clientTLSConfig *tls.Config, opts ...ServerOption) *grpc.Server {
+	o := newServerOptions(opts)
 	creds := credentials.NewTLS(clientTLSConfig)
 
+	m := o.registry.GRPCServerMetrics()
+	panics := o.registry.GRPCServerPanicsTotal()
+
 	rpcSrv := grpc.NewServer(
 		grpc.Creds(creds),
 		grpc.ChainUnaryInterceptor(
-			serverMetricInterceptor,
+			serverMetricInterceptor(m),
 			serverLogInterceptor(log),
+			serverRecoveryInterceptor(log, panics),
+		),
+		grpc.ChainStreamInterceptor(
+			serverMetricStreamInterceptor(m),
+			serverLogStreamInterceptor(log),
+			serverRecoveryStreamInterceptor(log, panics),
 		),
 	)
 
+	// the caller owns the health server, GracefulStop shuts it down before the server
+	if o.health != nil {
+		healthpb.RegisterHealthServer(rpcSrv, o.health)
+		o.health.Start()
+	}
+
 	return rpcSrv
 }
 
+// GracefulStop sets every service of the health server NOT_SERVING, so load balancers stop sending
+// new requests, and waits for running RPCs. RPCs still running when ctx is done are cancelled,
+// e.g. health Watch streams which never end. health may be nil.
+func GracefulStop(ctx context.Context, srv *grpc.Server, health *HealthServer) {
+	if health != nil {
+		health.Shutdown()
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		srv.Stop()
+		<-stopped
+	}
+}
+
 func serverLogInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
 	log = log.With(slog.String("component", "grpc server"))
 	tracer := otel.GetTracerProvider().Tracer("grpc server")
 
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		log.Info("[Storage Service Interceptor]", slog.Any("info", info.FullMethod))
-
-		// Extract TraceID from header
-		md, _ := metadata.FromIncomingContext(ctx)
-		traceIdString := md["x-trace-id"][0]
-		// Convert string to byte array
-		traceId, err := trace.TraceIDFromHex(traceIdString)
-		if err != nil {
-			return nil, err
-		}
-
-		// Creating a span context with a predefined trace-id
-		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
-			TraceID: traceId,
-		})
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (m interface{}, err error) {
+		// missing or malformed trace metadata starts a new trace
+		ctx = extractContext(ctx)
 
-		// Embedding span config into the context
-		ctx = trace.ContextWithSpanContext(ctx, spanContext)
+		ctx, span := tracer.Start(ctx, info.FullMethod, trace.WithSpanKind(trace.SpanKindServer))
+		defer func() {
+			endSpan(span, err)
+		}()
 
-		ctx, span := tracer.Start(ctx, info.FullMethod)
-		defer span.End()
+		log.InfoContext(ctx, "[Storage Service Interceptor]", slog.Any("info", info.FullMethod))
 
-		m, err := handler(ctx, req)
+		m, err = handler(ctx, req)
 
-		log.Info("post proc message", slog.Any("msg", m))
+		log.InfoContext(ctx, "post proc message", slog.Any("msg", m))
 
 		return m, err
 	}
 }
-
-func serverMetricInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	metrics.GRPCServerRequestsCount.With(map[string]string{
-		"method": info.FullMethod,
-	}).Inc()
-
-	m, err := handler(ctx, req)
-
-	return m, err
-}
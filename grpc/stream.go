@@ -0,0 +1,227 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const messageEvent = "message"
+
+// streamSpan adds an event per sent and received message to the span of a stream
+type streamSpan struct {
+	span     trace.Span
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (s *streamSpan) messageSent() {
+	id := s.sent.Add(1)
+	s.span.AddEvent(messageEvent, trace.WithAttributes(semconv.RPCMessageTypeSent, semconv.RPCMessageIDKey.Int64(id)))
+}
+
+func (s *streamSpan) messageReceived() {
+	id := s.received.Add(1)
+	s.span.AddEvent(messageEvent, trace.WithAttributes(semconv.RPCMessageTypeReceived, semconv.RPCMessageIDKey.Int64(id)))
+}
+
+func (s *streamSpan) end(err error) {
+	endSpan(s.span, err)
+}
+
+// endSpan sets the gRPC status of the unary or stream RPC on the span and ends it
+func endSpan(span trace.Span, err error) {
+	st := status.Convert(err)
+
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(st.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, st.Message())
+	}
+
+	span.End()
+}
+
+func (s *streamSpan) logAttrs(method string, start time.Time, err error) []slog.Attr {
+	return []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int64("sent", s.sent.Load()),
+		slog.Int64("received", s.received.Load()),
+	}
+}
+
+func serverLogStreamInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	log = log.With(slog.String("component", "grpc server"))
+	tracer := otel.GetTracerProvider().Tracer("grpc server")
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		ctx := extractContext(ss.Context())
+		ctx, span := tracer.Start(ctx, info.FullMethod, trace.WithSpanKind(trace.SpanKindServer))
+
+		s := &streamSpan{span: span}
+
+		log.InfoContext(ctx, "stream started", slog.String("method", info.FullMethod))
+
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx, s: s})
+
+		attrs := s.logAttrs(info.FullMethod, start, err)
+		if err != nil {
+			log.ErrorContext(ctx, "stream failed", err, attrs...)
+		} else {
+			log.InfoContext(ctx, "stream finished", attrs...)
+		}
+
+		s.end(err)
+
+		return err
+	}
+}
+
+// tracedServerStream passes the context with the server span to the handler
+type tracedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	s   *streamSpan
+}
+
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *tracedServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.s.messageSent()
+	}
+
+	return err
+}
+
+func (s *tracedServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.s.messageReceived()
+	}
+
+	return err
+}
+
+func clientLogStreamInterceptor(log *logger.Logger) grpc.StreamClientInterceptor {
+	log = log.With(slog.String("component", "grpc client"))
+	tracer := otel.GetTracerProvider().Tracer("grpc client")
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+		ctx = injectContext(ctx)
+
+		s := &streamSpan{span: span}
+
+		log.InfoContext(ctx, "stream started", slog.String("method", method))
+
+		finish := func(err error) {
+			attrs := s.logAttrs(method, start, err)
+			if err != nil {
+				log.ErrorContext(ctx, "stream failed", err, attrs...)
+			} else {
+				log.InfoContext(ctx, "stream finished", attrs...)
+			}
+
+			s.end(err)
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			finish(err)
+			return nil, err
+		}
+
+		stream := &tracedClientStream{
+			ClientStream:  cs,
+			s:             s,
+			serverStreams: desc.ServerStreams,
+			finish:        finish,
+			done:          make(chan struct{}),
+		}
+
+		finishOnDone(ctx, stream.done, stream.end)
+
+		return stream, nil
+	}
+}
+
+// finishOnDone calls finish with the context error when ctx is done before the stream ends,
+// so streams abandoned by the caller are reported as well. done is closed when the stream ends.
+func finishOnDone(ctx context.Context, done <-chan struct{}, finish func(error)) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			finish(status.FromContextError(ctx.Err()).Err())
+		case <-done:
+		}
+	}()
+}
+
+// tracedClientStream ends the span when receiving ends or the context of the stream is done,
+// so the caller must receive until io.EOF, the single reply of client streaming RPCs, or cancel the context
+type tracedClientStream struct {
+	grpc.ClientStream
+	s             *streamSpan
+	serverStreams bool
+	finish        func(error)
+	once          sync.Once
+	done          chan struct{}
+}
+
+func (s *tracedClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.s.messageSent()
+	}
+
+	return err
+}
+
+func (s *tracedClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	switch {
+	case err == nil:
+		s.s.messageReceived()
+
+		if !s.serverStreams {
+			s.end(nil)
+		}
+	case errors.Is(err, io.EOF):
+		s.end(nil)
+	default:
+		s.end(err)
+	}
+
+	return err
+}
+
+func (s *tracedClientStream) end(err error) {
+	s.once.Do(func() {
+		s.finish(err)
+		close(s.done)
+	})
+}
@@ -0,0 +1,470 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/logger"
+	"github.com/TakeAway-Inc/platform/metrics"
+	"github.com/TakeAway-Inc/platform/tracing/tracingtest"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testService       = "grpc.testing.TestService"
+	unaryMethod       = "/" + testService + "/UnaryCall"
+	serverStreamCall  = "/" + testService + "/StreamingOutputCall"
+	clientStreamCall  = "/" + testService + "/StreamingInputCall"
+	spanWaitTimeout   = time.Second
+	spanWaitInterval  = 5 * time.Millisecond
+	settleAfterFinish = 50 * time.Millisecond
+)
+
+// streamService streams one response per response parameter, then blocks until the client
+// goes away when block is set, or returns err
+type streamService struct {
+	testpb.UnimplementedTestServiceServer
+	block bool
+	err   error
+}
+
+func (s *streamService) UnaryCall(context.Context, *testpb.SimpleRequest) (*testpb.SimpleResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &testpb.SimpleResponse{}, nil
+}
+
+func (s *streamService) StreamingOutputCall(req *testpb.StreamingOutputCallRequest, stream grpc.ServerStreamingServer[testpb.StreamingOutputCallResponse]) error {
+	for range req.GetResponseParameters() {
+		if err := stream.Send(&testpb.StreamingOutputCallResponse{}); err != nil {
+			return err
+		}
+	}
+
+	if s.block {
+		<-stream.Context().Done()
+		return status.FromContextError(stream.Context().Err()).Err()
+	}
+
+	return s.err
+}
+
+func (s *streamService) StreamingInputCall(stream grpc.ClientStreamingServer[testpb.StreamingInputCallRequest, testpb.StreamingInputCallResponse]) error {
+	var received int32
+
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&testpb.StreamingInputCallResponse{AggregatedPayloadSize: received})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		received++
+	}
+}
+
+// logRecorder keeps messages of the records by component, installed as the default slog handler
+type logRecorder struct {
+	mu        *sync.Mutex
+	messages  map[string][]string
+	component string
+}
+
+func newLogRecorder(t *testing.T) *logRecorder {
+	t.Setenv("APP_ENV", "")
+	t.Setenv("LOKI_URL", "")
+	t.Setenv("LOG_SAMPLING_FIRST", "")
+
+	rec := &logRecorder{mu: &sync.Mutex{}, messages: make(map[string][]string)}
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(rec))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	return rec
+}
+
+func (r *logRecorder) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (r *logRecorder) Handle(_ context.Context, record slog.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages[r.component] = append(r.messages[r.component], record.Message)
+
+	return nil
+}
+
+func (r *logRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *r
+	for _, attr := range attrs {
+		if attr.Key == "component" {
+			c.component = attr.Value.String()
+		}
+	}
+
+	return &c
+}
+
+func (r *logRecorder) WithGroup(string) slog.Handler {
+	return r
+}
+
+// count returns the number of records of the component with any of the messages
+func (r *logRecorder) count(component string, messages ...string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+
+	for _, msg := range r.messages[component] {
+		for _, m := range messages {
+			if msg == m {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// assertFinishedOnce checks the client stream finished exactly once
+func (r *logRecorder) assertFinishedOnce(t *testing.T) {
+	t.Helper()
+
+	if n := r.count("grpc client", "stream finished", "stream failed"); n != 1 {
+		t.Errorf("client stream finished %d times, want 1", n)
+	}
+}
+
+// newTestClient serves svc over an in-memory connection, both sides use the platform interceptors
+func newTestClient(t *testing.T, svc testpb.TestServiceServer) (testpb.TestServiceClient, *metrics.GRPCMetrics, *logRecorder) {
+	t.Helper()
+
+	logs := newLogRecorder(t)
+	log := logger.New()
+	lis := bufconn.Listen(1 << 20)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(serverLogInterceptor(log)),
+		grpc.ChainStreamInterceptor(serverLogStreamInterceptor(log)),
+	)
+	testpb.RegisterTestServiceServer(srv, svc)
+
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	t.Cleanup(srv.Stop)
+
+	m := metrics.NewRegistry().GRPCClientMetrics()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(clientLogInterceptor(log), clientMetricInterceptor(m)),
+		grpc.WithChainStreamInterceptor(clientLogStreamInterceptor(log), clientMetricStreamInterceptor(m)),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return testpb.NewTestServiceClient(conn), m, logs
+}
+
+func outputRequest(responses int) *testpb.StreamingOutputCallRequest {
+	return &testpb.StreamingOutputCallRequest{ResponseParameters: make([]*testpb.ResponseParameters, responses)}
+}
+
+// waitSpan waits until the span of the kind ends and checks no other span of the RPC is recorded
+func waitSpan(t *testing.T, rec *tracingtest.Recorder, name string, kind trace.SpanKind) tracetest.SpanStub {
+	t.Helper()
+
+	deadline := time.Now().Add(spanWaitTimeout)
+
+	for {
+		if spans := spansOfKind(rec, name, kind); len(spans) > 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("%s span %q did not end", kind, name)
+		}
+
+		time.Sleep(spanWaitInterval)
+	}
+
+	// let a late finish, e.g. of the context watcher, happen before asserting
+	time.Sleep(settleAfterFinish)
+
+	spans := spansOfKind(rec, name, kind)
+	if len(spans) != 1 {
+		t.Fatalf("%s spans %q = %d, want 1", kind, name, len(spans))
+	}
+
+	return spans[0]
+}
+
+func spansOfKind(rec *tracingtest.Recorder, name string, kind trace.SpanKind) []tracetest.SpanStub {
+	var spans []tracetest.SpanStub
+
+	for _, span := range rec.ByName(name) {
+		if span.SpanKind == kind {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+func assertSpanCode(t *testing.T, span tracetest.SpanStub, code codes.Code) {
+	t.Helper()
+
+	got, ok := tracingtest.Attr(span, semconv.RPCGRPCStatusCodeKey)
+	if !ok || got.AsInt64() != int64(code) {
+		t.Errorf("%s span %q status code = %v, want %d", span.SpanKind, span.Name, got.Emit(), code)
+	}
+
+	wantStatus := otelcodes.Unset
+	if code != codes.OK {
+		wantStatus = otelcodes.Error
+	}
+
+	if span.Status.Code != wantStatus {
+		t.Errorf("%s span %q status = %s, want %s", span.SpanKind, span.Name, span.Status.Code, wantStatus)
+	}
+}
+
+// assertHandled checks the RPC was reported as handled exactly once with the code
+func assertHandled(t *testing.T, m *metrics.GRPCMetrics, rpcType, method string, code codes.Code) {
+	t.Helper()
+
+	if n := testutil.CollectAndCount(m.HandledTotal); n != 1 {
+		t.Errorf("handled series = %d, want 1", n)
+	}
+
+	if got := testutil.ToFloat64(m.HandledTotal.WithLabelValues(rpcType, testService, method, code.String())); got != 1 {
+		t.Errorf("handled %s = %v, want 1", code, got)
+	}
+}
+
+func TestClientStreamServerStreamingToEOF(t *testing.T) {
+	rec := tracingtest.New(t)
+	client, m, logs := newTestClient(t, &streamService{})
+
+	stream, err := client.StreamingOutputCall(context.Background(), outputRequest(3))
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+
+	received := 0
+
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+
+		received++
+	}
+
+	// receiving after the end must not finish the stream again
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("recv after EOF = %v, want EOF", err)
+	}
+
+	if received != 3 {
+		t.Fatalf("received = %d, want 3", received)
+	}
+
+	span := waitSpan(t, rec, serverStreamCall, trace.SpanKindClient)
+	assertSpanCode(t, span, codes.OK)
+
+	if len(span.Events) != 4 {
+		t.Errorf("message events = %d, want 4", len(span.Events))
+	}
+
+	server := waitSpan(t, rec, serverStreamCall, trace.SpanKindServer)
+	if server.Parent.SpanID() != span.SpanContext.SpanID() {
+		t.Errorf("server span parent = %s, want client span %s", server.Parent.SpanID(), span.SpanContext.SpanID())
+	}
+
+	assertHandled(t, m, serverStream, "StreamingOutputCall", codes.OK)
+	logs.assertFinishedOnce(t)
+}
+
+func TestClientStreamCloseAndRecv(t *testing.T) {
+	rec := tracingtest.New(t)
+	client, m, logs := newTestClient(t, &streamService{})
+
+	stream, err := client.StreamingInputCall(context.Background())
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+
+	for range 2 {
+		if err := stream.Send(&testpb.StreamingInputCallRequest{}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("close and recv: %v", err)
+	}
+
+	if resp.GetAggregatedPayloadSize() != 2 {
+		t.Errorf("server received = %d, want 2", resp.GetAggregatedPayloadSize())
+	}
+
+	span := waitSpan(t, rec, clientStreamCall, trace.SpanKindClient)
+	assertSpanCode(t, span, codes.OK)
+	assertHandled(t, m, clientStream, "StreamingInputCall", codes.OK)
+	logs.assertFinishedOnce(t)
+}
+
+func TestClientStreamServerError(t *testing.T) {
+	rec := tracingtest.New(t)
+	client, m, logs := newTestClient(t, &streamService{err: status.Error(codes.NotFound, "no such order")})
+
+	stream, err := client.StreamingOutputCall(context.Background(), outputRequest(1))
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+
+	if _, err := stream.Recv(); status.Code(err) != codes.NotFound {
+		t.Fatalf("recv = %v, want NotFound", err)
+	}
+
+	assertSpanCode(t, waitSpan(t, rec, serverStreamCall, trace.SpanKindClient), codes.NotFound)
+	assertSpanCode(t, waitSpan(t, rec, serverStreamCall, trace.SpanKindServer), codes.NotFound)
+	assertHandled(t, m, serverStream, "StreamingOutputCall", codes.NotFound)
+	logs.assertFinishedOnce(t)
+}
+
+func TestClientStreamCancelled(t *testing.T) {
+	rec := tracingtest.New(t)
+	client, m, logs := newTestClient(t, &streamService{block: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.StreamingOutputCall(ctx, outputRequest(1))
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+
+	cancel()
+
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("recv = %v, want Canceled", err)
+	}
+
+	assertSpanCode(t, waitSpan(t, rec, serverStreamCall, trace.SpanKindClient), codes.Canceled)
+	assertHandled(t, m, serverStream, "StreamingOutputCall", codes.Canceled)
+	logs.assertFinishedOnce(t)
+}
+
+func TestClientStreamAbandoned(t *testing.T) {
+	rec := tracingtest.New(t)
+	client, m, logs := newTestClient(t, &streamService{block: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := client.StreamingOutputCall(ctx, outputRequest(1))
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+
+	// the caller stops receiving and only cancels the context
+	cancel()
+
+	assertSpanCode(t, waitSpan(t, rec, serverStreamCall, trace.SpanKindClient), codes.Canceled)
+	assertHandled(t, m, serverStream, "StreamingOutputCall", codes.Canceled)
+	logs.assertFinishedOnce(t)
+}
+
+func TestUnarySpanStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "ok", code: codes.OK},
+		{name: "error", err: status.Error(codes.PermissionDenied, "denied"), code: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := tracingtest.New(t)
+			client, m, logs := newTestClient(t, &streamService{err: tt.err})
+
+			_, err := client.UnaryCall(context.Background(), &testpb.SimpleRequest{})
+			if status.Code(err) != tt.code {
+				t.Fatalf("call = %v, want %s", err, tt.code)
+			}
+
+			clientSpan := waitSpan(t, rec, unaryMethod, trace.SpanKindClient)
+			serverSpan := waitSpan(t, rec, unaryMethod, trace.SpanKindServer)
+
+			assertSpanCode(t, clientSpan, tt.code)
+			assertSpanCode(t, serverSpan, tt.code)
+
+			if serverSpan.Parent.SpanID() != clientSpan.SpanContext.SpanID() {
+				t.Errorf("server span parent = %s, want client span %s", serverSpan.Parent.SpanID(), clientSpan.SpanContext.SpanID())
+			}
+
+			assertHandled(t, m, unary, "UnaryCall", tt.code)
+
+			if n := logs.count("grpc client", "failed to invoke", "got reply"); n != 1 {
+				t.Errorf("client call logged %d times, want 1", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceIDKey = "trace_id"
+	spanIDKey  = "span_id"
+)
+
+type ctxAttrsKey struct{}
+
+// ContextWithAttrs returns a copy of ctx carrying request-scoped attributes
+// which are added to every record logged with that context.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	parent := AttrsFromContext(ctx)
+
+	merged := make([]slog.Attr, 0, len(parent)+len(attrs))
+	merged = append(merged, parent...)
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, ctxAttrsKey{}, merged)
+}
+
+// AttrsFromContext returns request-scoped attributes stored in ctx
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+
+	return attrs
+}
+
+// ContextHandler adds trace_id and span_id of the active span and
+// request-scoped attributes from the context to every record.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx == nil {
+		return h.Handler.Handle(ctx, record)
+	}
+
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record.AddAttrs(
+			slog.String(traceIDKey, spanCtx.TraceID().String()),
+			slog.String(spanIDKey, spanCtx.SpanID().String()),
+		)
+	}
+
+	record.AddAttrs(AttrsFromContext(ctx)...)
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewContextHandler(h.Handler.WithAttrs(attrs))
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return NewContextHandler(h.Handler.WithGroup(name))
+}
@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+)
+
+// minLevel lets every record through the inner handlers,
+// filtering is done by LevelHandler.
+const minLevel = slog.Level(math.MinInt)
+
+// componentKeys are attribute keys naming the package which logs, e.g. "grpc client", "postgres"
+var componentKeys = []string{"component", "module"}
+
+// LevelRegistry holds the default level and per-component overrides which can be changed at runtime.
+type LevelRegistry struct {
+	def     *slog.LevelVar
+	initial slog.Level
+
+	mu     sync.RWMutex
+	levels map[string]*slog.LevelVar
+	// known are components of the loggers created so far
+	known map[string]struct{}
+}
+
+func NewLevelRegistry(level slog.Level) *LevelRegistry {
+	def := &slog.LevelVar{}
+	def.Set(level)
+
+	return &LevelRegistry{
+		def:     def,
+		initial: level,
+		levels:  make(map[string]*slog.LevelVar),
+		known:   make(map[string]struct{}),
+	}
+}
+
+// Known reports whether a logger of the component was created or the component has an override
+func (r *LevelRegistry) Known(component string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, known := r.known[component]
+	_, overridden := r.levels[component]
+
+	return known || overridden
+}
+
+func (r *LevelRegistry) register(component string) {
+	r.mu.RLock()
+	_, ok := r.known[component]
+	r.mu.RUnlock()
+
+	if ok {
+		return
+	}
+
+	r.mu.Lock()
+	r.known[component] = struct{}{}
+	r.mu.Unlock()
+}
+
+// Level returns the level of the component, the default level when it has no override
+func (r *LevelRegistry) Level(component string) slog.Level {
+	if component != "" {
+		r.mu.RLock()
+		v, ok := r.levels[component]
+		r.mu.RUnlock()
+
+		if ok {
+			return v.Level()
+		}
+	}
+
+	return r.def.Level()
+}
+
+// SetLevel overrides the level of the component, empty component sets the default level
+func (r *LevelRegistry) SetLevel(component string, level slog.Level) {
+	if component == "" {
+		r.def.Set(level)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.levels[component]
+	if !ok {
+		v = &slog.LevelVar{}
+		r.levels[component] = v
+	}
+
+	v.Set(level)
+}
+
+// ResetLevel removes the override of the component, empty component restores the initial default level
+func (r *LevelRegistry) ResetLevel(component string) {
+	if component == "" {
+		r.def.Set(r.initial)
+		return
+	}
+
+	r.mu.Lock()
+	delete(r.levels, component)
+	r.mu.Unlock()
+}
+
+// ResetAll restores the initial default level and removes all overrides
+func (r *LevelRegistry) ResetAll() {
+	r.def.Set(r.initial)
+
+	r.mu.Lock()
+	clear(r.levels)
+	r.mu.Unlock()
+}
+
+// Levels returns the default level under the "default" key and all overrides
+func (r *LevelRegistry) Levels() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[string]string, len(r.levels)+1)
+	levels["default"] = r.def.Level().String()
+
+	for component, v := range r.levels {
+		levels[component] = v.Level().String()
+	}
+
+	return levels
+}
+
+// ParseLevel parses level names like "debug", "INFO" or "warn+2"
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: %w", s, err)
+	}
+
+	return level, nil
+}
+
+// parseComponentLevels parses overrides in the form "postgres=debug,grpc client=warn"
+func parseComponentLevels(r *LevelRegistry, s string) {
+	for _, pair := range strings.Split(s, ",") {
+		component, name, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			continue
+		}
+
+		r.SetLevel(strings.TrimSpace(component), level)
+	}
+}
+
+// LevelHandler filters records by the level of the component taken
+// from the "component" or "module" attribute set with Logger.With.
+type LevelHandler struct {
+	handler   slog.Handler
+	registry  *LevelRegistry
+	component string
+}
+
+func NewLevelHandler(handler slog.Handler, registry *LevelRegistry) *LevelHandler {
+	return &LevelHandler{
+		handler:  handler,
+		registry: registry,
+	}
+}
+
+func (h *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.registry.Level(h.component) && h.handler.Enabled(ctx, level)
+}
+
+func (h *LevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+
+	for _, attr := range attrs {
+		for _, key := range componentKeys {
+			if attr.Key == key && attr.Value.Kind() == slog.KindString {
+				component = attr.Value.String()
+			}
+		}
+	}
+
+	if component != h.component {
+		h.registry.register(component)
+	}
+
+	return &LevelHandler{
+		handler:   h.handler.WithAttrs(attrs),
+		registry:  h.registry,
+		component: component,
+	}
+}
+
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{
+		handler:   h.handler.WithGroup(name),
+		registry:  h.registry,
+		component: h.component,
+	}
+}
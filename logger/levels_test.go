@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestLevelHandlerComponents(t *testing.T) {
+	log, rec := NewTest(t)
+	levels := log.Levels()
+
+	levels.SetLevel("", slog.LevelInfo)
+	levels.SetLevel("postgres", slog.LevelDebug)
+	levels.SetLevel("redis", slog.LevelError)
+
+	tests := []struct {
+		name   string
+		log    *Logger
+		level  slog.Level
+		logged bool
+	}{
+		{name: "default level", log: log, level: slog.LevelInfo, logged: true},
+		{name: "below default level", log: log, level: slog.LevelDebug},
+		{name: "component override", log: log.With(slog.String("component", "postgres")), level: slog.LevelDebug, logged: true},
+		{name: "module override", log: log.With(slog.String("module", "redis")), level: slog.LevelWarn},
+		{name: "module override error", log: log.With(slog.String("module", "redis")), level: slog.LevelError, logged: true},
+		{name: "component without override", log: log.With(slog.String("component", "grpc client")), level: slog.LevelDebug},
+		{
+			name:   "nested logger keeps component",
+			log:    log.With(slog.String("component", "postgres")).With(slog.String("table", "orders")),
+			level:  slog.LevelDebug,
+			logged: true,
+		},
+		{
+			name:  "latest component wins",
+			log:   log.With(slog.String("component", "postgres")).With(slog.String("module", "redis")),
+			level: slog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec.Reset()
+
+			tt.log.LogContext(context.Background(), tt.level, "message")
+
+			if logged := rec.Contains("message"); logged != tt.logged {
+				t.Errorf("logged = %v, want %v", logged, tt.logged)
+			}
+		})
+	}
+}
+
+func TestLevelRegistryKnown(t *testing.T) {
+	log, _ := NewTest(t)
+	levels := log.Levels()
+
+	parseComponentLevels(levels, "minio=warn")
+	log.With(slog.String("component", "grpc client"))
+	log.With(slog.String("module", "postgres"))
+
+	for component, want := range map[string]bool{
+		"grpc client": true,
+		"postgres":    true,
+		"minio":       true,
+		"redis":       false,
+		"":            false,
+	} {
+		if got := levels.Known(component); got != want {
+			t.Errorf("Known(%q) = %v, want %v", component, got, want)
+		}
+	}
+}
+
+func TestLevelRegistryReset(t *testing.T) {
+	levels := NewLevelRegistry(slog.LevelInfo)
+
+	levels.SetLevel("", slog.LevelWarn)
+	levels.SetLevel("postgres", slog.LevelDebug)
+	levels.SetLevel("redis", slog.LevelError)
+
+	levels.ResetLevel("postgres")
+
+	if got := levels.Level("postgres"); got != slog.LevelWarn {
+		t.Errorf("postgres level after reset = %s, want default %s", got, slog.LevelWarn)
+	}
+
+	levels.ResetLevel("")
+
+	if got := levels.Level(""); got != slog.LevelInfo {
+		t.Errorf("default level after reset = %s, want initial %s", got, slog.LevelInfo)
+	}
+
+	if got := levels.Level("redis"); got != slog.LevelError {
+		t.Errorf("redis level = %s, want %s", got, slog.LevelError)
+	}
+
+	levels.SetLevel("", slog.LevelDebug)
+	levels.ResetAll()
+
+	want := map[string]string{"default": slog.LevelInfo.String()}
+	if got := levels.Levels(); len(got) != len(want) || got["default"] != want["default"] {
+		t.Errorf("levels after reset all = %v, want %v", got, want)
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	levels := NewLevelRegistry(slog.LevelInfo)
+
+	parseComponentLevels(levels, "postgres=debug, grpc client = warn,broken,redis=loud")
+
+	want := map[string]string{
+		"default":     "INFO",
+		"postgres":    "DEBUG",
+		"grpc client": "WARN",
+	}
+
+	got := levels.Levels()
+	if len(got) != len(want) {
+		t.Fatalf("levels = %v, want %v", got, want)
+	}
+
+	for component, level := range want {
+		if got[component] != level {
+			t.Errorf("level of %q = %s, want %s", component, got[component], level)
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Logger is a concrete implementation of the Logger interface using slog.Logger.
 type Logger struct {
-	l *slog.Logger
+	l      *slog.Logger
+	loki   *LokiHandler
+	levels *LevelRegistry
+	// stopSignals unsubscribes from the level signals, nil when LOG_SIGNALS is off
+	stopSignals func()
 }
 
 // convertAttrsToAny converts []slog.Attr to []any for slog.Logger methods
@@ -39,6 +47,28 @@ func (l *Logger) Error(msg string, err error, attrs ...slog.Attr) {
 	l.l.Error(msg, convertAttrsToAny(attrs)...)
 }
 
+func (l *Logger) InfoContext(ctx context.Context, msg string, attrs ...slog.Attr) {
+	l.l.InfoContext(ctx, msg, convertAttrsToAny(attrs)...)
+}
+
+func (l *Logger) WarnContext(ctx context.Context, msg string, attrs ...slog.Attr) {
+	l.l.WarnContext(ctx, msg, convertAttrsToAny(attrs)...)
+}
+
+func (l *Logger) DebugContext(ctx context.Context, msg string, attrs ...slog.Attr) {
+	l.l.DebugContext(ctx, msg, convertAttrsToAny(attrs)...)
+}
+
+func (l *Logger) ErrorContext(ctx context.Context, msg string, err error, attrs ...slog.Attr) {
+	attrs = append(attrs, slog.Any("error", err))
+	l.l.ErrorContext(ctx, msg, convertAttrsToAny(attrs)...)
+}
+
+// LogContext logs at the given level, used when the level is known only at runtime
+func (l *Logger) LogContext(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	l.l.LogAttrs(ctx, level, msg, attrs...)
+}
+
 func (l *Logger) With(attrs ...slog.Attr) *Logger {
 	c := l.clone()
 	c.l = c.l.With(convertAttrsToAny(attrs)...)
@@ -48,6 +78,11 @@ func (l *Logger) With(attrs ...slog.Attr) *Logger {
 const defaultLevel = slog.LevelDebug
 
 // New creates a new instance of logger based on environment settings.
+// When LOKI_URL is set records are also pushed into Loki.
+// Sensitive attributes are masked in prod, see LOG_REDACT.
+// LOG_LEVELS overrides levels of components, e.g. "postgres=debug,grpc client=warn".
+// LOG_SAMPLING_FIRST enables sampling of repeated records, see samplingOptions.
+// LOG_SIGNALS=true switches every component to debug on SIGUSR1 and back on SIGUSR2.
 func New() *Logger {
 	var handler slog.Handler
 
@@ -56,30 +91,133 @@ func New() *Logger {
 	} else if env == "dev" {
 		handler = devHandler()
 	} else {
-		return &Logger{
-			l: slog.Default(),
-		}
+		handler = slog.Default().Handler()
 	}
 
-	return &Logger{l: slog.New(handler)}
+	l := &Logger{levels: NewLevelRegistry(configLevel())}
+
+	parseComponentLevels(l.levels, os.Getenv("LOG_LEVELS"))
+
+	if enabled(os.Getenv("LOG_SIGNALS")) {
+		l.stopSignals = sync.OnceFunc(l.levels.NotifySignals())
+	}
+
+	if url := os.Getenv("LOKI_URL"); url != "" {
+		l.loki = NewLokiHandler(lokiConfig(url))
+		handler = newFanoutHandler(handler, l.loki)
+	}
+
+	if redactEnabled() {
+		handler = NewRedactHandler(handler, DefaultRedactOptions())
+	}
+
+	if opts := samplingOptions(); opts != nil {
+		handler = NewSamplingHandler(handler, opts)
+	}
+
+	l.l = slog.New(NewContextHandler(NewLevelHandler(handler, l.levels)))
+
+	return l
+}
+
+// Levels returns the registry used to change levels of components at runtime
+func (l *Logger) Levels() *LevelRegistry {
+	return l.levels
+}
+
+// Close flushes records buffered for Loki and stops listening to the level signals
+func (l *Logger) Close() error {
+	if l.stopSignals != nil {
+		l.stopSignals()
+	}
+
+	if l.loki == nil {
+		return nil
+	}
+
+	return l.loki.Close()
 }
 
 // prodHandler configures the handler for production environments
 func prodHandler() slog.Handler {
 	return slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: configLevel(),
+		Level: minLevel,
 	})
 }
 
 // devHandler configures the handler for development environments
 func devHandler() slog.Handler {
 	return slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     configLevel(),
+		Level:     minLevel,
 		AddSource: true,
 	})
 }
 
-// configLevel sets the logging level based on the LOG_LEVEL environment variable
+// lokiConfig configures the Loki handler, streams are labelled by SERVICE_NAME and APP_ENV
+func lokiConfig(url string) *LokiConfig {
+	labels := map[string]string{
+		"service": valueOr(os.Getenv("SERVICE_NAME"), defaultLokiService),
+	}
+
+	if env := os.Getenv("APP_ENV"); env != "" {
+		labels["env"] = env
+	}
+
+	return &LokiConfig{
+		URL:         url,
+		Labels:      labels,
+		Compression: strings.ToLower(os.Getenv("LOKI_COMPRESSION")),
+		Level:       minLevel,
+	}
+}
+
+// redactEnabled reports whether sensitive data must be masked.
+// Redaction is on in prod unless LOG_REDACT overrides it.
+func redactEnabled() bool {
+	switch strings.ToLower(os.Getenv("LOG_REDACT")) {
+	case "true", "1":
+		return true
+	case "false", "0":
+		return false
+	default:
+		return os.Getenv("APP_ENV") == "prod"
+	}
+}
+
+// enabled reports whether the flag value is "true" or "1"
+func enabled(val string) bool {
+	switch strings.ToLower(val) {
+	case "true", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+// samplingOptions configures sampling from LOG_SAMPLING_FIRST, LOG_SAMPLING_THEREAFTER
+// and LOG_SAMPLING_INTERVAL, sampling is off when LOG_SAMPLING_FIRST is not set.
+// LOG_SAMPLING_THEREAFTER is 100 when not set, explicit 0 drops every record after the first ones.
+func samplingOptions() *SamplingOptions {
+	first, err := strconv.ParseUint(os.Getenv("LOG_SAMPLING_FIRST"), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	thereafter, err := strconv.ParseUint(os.Getenv("LOG_SAMPLING_THEREAFTER"), 10, 64)
+	if err != nil {
+		thereafter = defaultSamplingThereafter
+	}
+
+	interval, _ := time.ParseDuration(os.Getenv("LOG_SAMPLING_INTERVAL"))
+
+	return &SamplingOptions{
+		Interval:   interval,
+		First:      first,
+		Thereafter: thereafter,
+	}
+}
+
+// configLevel sets the default logging level based on the LOG_LEVEL environment variable
 func configLevel() slog.Level {
 	var logLevel slog.Level
 
@@ -0,0 +1,475 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	lokiPushPath = "/loki/api/v1/push"
+
+	defaultLokiBatchSize  = 1024
+	defaultLokiBatchWait  = time.Second
+	defaultLokiBufferSize = 8192
+	defaultLokiTimeout    = 10 * time.Second
+	defaultLokiMaxRetries = 5
+	defaultLokiMinBackoff = 500 * time.Millisecond
+	defaultLokiMaxBackoff = 10 * time.Second
+
+	// defaultLokiService labels streams when no labels are configured, Loki rejects streams without labels
+	defaultLokiService = "unknown"
+)
+
+// Reasons of dropped records
+const (
+	lokiDropBufferFull = "buffer_full"
+	lokiDropEncode     = "encode"
+	lokiDropRejected   = "rejected"
+	lokiDropShutdown   = "shutdown"
+)
+
+// Compression used for the Loki push request body.
+const (
+	CompressionNone   = ""
+	CompressionGzip   = "gzip"
+	CompressionSnappy = "snappy"
+)
+
+var ErrLokiClosed = errors.New("loki handler is closed")
+
+// LokiConfig configures the Loki push handler.
+type LokiConfig struct {
+	// URL is the Loki base address, e.g. http://localhost:3100.
+	// The push path is appended when it is missing.
+	URL string
+	// Labels are attached to every stream pushed by the handler,
+	// service="unknown" is used when empty.
+	Labels map[string]string
+	// Compression is one of CompressionNone, CompressionGzip or CompressionSnappy.
+	// Gzip sends a JSON body, snappy sends a protobuf body as Loki expects.
+	Compression string
+	// Level is the minimal level passed to Loki.
+	Level slog.Leveler
+
+	BatchSize  int
+	BatchWait  time.Duration
+	BufferSize int
+	Timeout    time.Duration
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Client is used to send push requests, http.DefaultClient when nil.
+	Client *http.Client
+	// Registry counts dropped records, metrics.Default when nil.
+	Registry *metrics.Registry
+}
+
+// LokiHandler is a slog.Handler which batches records and pushes them into Loki.
+type LokiHandler struct {
+	slog.Handler
+
+	client *lokiClient
+}
+
+// NewLokiHandler creates handler which formats records as JSON lines and
+// ships them into Loki in the background. Close must be called to flush
+// the buffered records.
+func NewLokiHandler(cfg *LokiConfig) *LokiHandler {
+	client := newLokiClient(cfg)
+
+	return &LokiHandler{
+		Handler: slog.NewJSONHandler(client, &slog.HandlerOptions{
+			Level: cfg.Level,
+		}),
+		client: client,
+	}
+}
+
+func (h *LokiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LokiHandler{Handler: h.Handler.WithAttrs(attrs), client: h.client}
+}
+
+func (h *LokiHandler) WithGroup(name string) slog.Handler {
+	return &LokiHandler{Handler: h.Handler.WithGroup(name), client: h.client}
+}
+
+// Dropped returns the number of records dropped because the buffer was full
+// or Loki rejected them.
+func (h *LokiHandler) Dropped() uint64 {
+	return h.client.dropped.Load()
+}
+
+// Close flushes buffered records and stops the background worker.
+func (h *LokiHandler) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.client.timeout)
+	defer cancel()
+
+	return h.Shutdown(ctx)
+}
+
+// Shutdown flushes buffered records and stops the background worker
+// within the context deadline.
+func (h *LokiHandler) Shutdown(ctx context.Context) error {
+	return h.client.shutdown(ctx)
+}
+
+type lokiEntry struct {
+	ts   time.Time
+	line string
+}
+
+type lokiClient struct {
+	url         string
+	labels      map[string]string
+	compression string
+	batchSize   int
+	batchWait   time.Duration
+	timeout     time.Duration
+	maxRetries  int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	httpClient  *http.Client
+
+	entries chan lokiEntry
+	quit    chan struct{}
+	done    chan struct{}
+
+	mu       sync.RWMutex
+	closed   bool
+	once     sync.Once
+	quitOnce sync.Once
+
+	dropped      atomic.Uint64
+	droppedTotal *prometheus.CounterVec
+}
+
+func newLokiClient(cfg *LokiConfig) *lokiClient {
+	labels := cfg.Labels
+	if len(labels) == 0 {
+		labels = map[string]string{"service": defaultLokiService}
+	}
+
+	registry := cfg.Registry
+	if registry == nil {
+		registry = metrics.Default()
+	}
+
+	c := &lokiClient{
+		url:         lokiURL(cfg.URL),
+		labels:      labels,
+		compression: cfg.Compression,
+		batchSize:   valueOr(cfg.BatchSize, defaultLokiBatchSize),
+		batchWait:   valueOr(cfg.BatchWait, defaultLokiBatchWait),
+		timeout:     valueOr(cfg.Timeout, defaultLokiTimeout),
+		maxRetries:  valueOr(cfg.MaxRetries, defaultLokiMaxRetries),
+		minBackoff:  valueOr(cfg.MinBackoff, defaultLokiMinBackoff),
+		maxBackoff:  valueOr(cfg.MaxBackoff, defaultLokiMaxBackoff),
+		httpClient:  cfg.Client,
+		entries:     make(chan lokiEntry, valueOr(cfg.BufferSize, defaultLokiBufferSize)),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+
+		droppedTotal: registry.LokiRecordsDropped(),
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	go c.run()
+
+	return c
+}
+
+// Write receives a single formatted record from the JSON handler.
+// It never blocks: records are dropped when the buffer is full.
+func (c *lokiClient) Write(p []byte) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return 0, ErrLokiClosed
+	}
+
+	select {
+	case c.entries <- lokiEntry{ts: time.Now(), line: strings.TrimSuffix(string(p), "\n")}:
+	default:
+		c.drop(1, lokiDropBufferFull, nil)
+	}
+
+	return len(p), nil
+}
+
+func (c *lokiClient) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.batchWait)
+	defer ticker.Stop()
+
+	batch := make([]lokiEntry, 0, c.batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		c.send(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-c.entries:
+			if !ok {
+				flush()
+				return
+			}
+
+			batch = append(batch, entry)
+			if len(batch) >= c.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (c *lokiClient) shutdown(ctx context.Context) error {
+	c.once.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		close(c.entries)
+		c.mu.Unlock()
+	})
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		// stop retries of the batch in flight, shutdown may be called again
+		c.quitOnce.Do(func() {
+			close(c.quit)
+		})
+
+		return ctx.Err()
+	}
+}
+
+// send pushes the batch retrying with exponential backoff on network errors,
+// 429 and 5xx responses.
+func (c *lokiClient) send(batch []lokiEntry) {
+	body, contentType, err := c.encode(batch)
+	if err != nil {
+		c.drop(len(batch), lokiDropEncode, err)
+		return
+	}
+
+	backoff := c.minBackoff
+
+	for attempt := 0; ; attempt++ {
+		retry, err := c.push(body, contentType)
+		if err == nil {
+			return
+		}
+
+		if !retry || attempt >= c.maxRetries {
+			c.drop(len(batch), lokiDropRejected, err)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-c.quit:
+			c.drop(len(batch), lokiDropShutdown, err)
+			return
+		}
+
+		backoff = min(backoff*2, c.maxBackoff)
+	}
+}
+
+// drop counts dropped records, failed pushes are also reported to stderr
+// because the logger can't log its own failures
+func (c *lokiClient) drop(n int, reason string, err error) {
+	c.dropped.Add(uint64(n))
+	c.droppedTotal.WithLabelValues(reason).Add(float64(n))
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loki: dropped %d records (%s): %v\n", n, reason, err)
+	}
+}
+
+func (c *lokiClient) push(body []byte, contentType string) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if c.compression == CompressionGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("loki push failed with status %d", resp.StatusCode)
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5, err
+}
+
+func (c *lokiClient) encode(batch []lokiEntry) ([]byte, string, error) {
+	switch c.compression {
+	case CompressionSnappy:
+		return snappy.Encode(nil, encodeLokiProto(c.labels, batch)), "application/x-protobuf", nil
+	case CompressionGzip:
+		body, err := encodeLokiJSON(c.labels, batch)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var buf bytes.Buffer
+
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return nil, "", err
+		}
+
+		if err := zw.Close(); err != nil {
+			return nil, "", err
+		}
+
+		return buf.Bytes(), "application/json", nil
+	default:
+		body, err := encodeLokiJSON(c.labels, batch)
+		return body, "application/json", err
+	}
+}
+
+// encodeLokiJSON builds the body in the JSON push format:
+// {"streams":[{"stream":{...},"values":[["<unix ns>","<line>"]]}]}
+func encodeLokiJSON(labels map[string]string, batch []lokiEntry) ([]byte, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	s := stream{
+		Stream: labels,
+		Values: make([][2]string, 0, len(batch)),
+	}
+
+	if s.Stream == nil {
+		s.Stream = map[string]string{}
+	}
+
+	for _, entry := range batch {
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(entry.ts.UnixNano(), 10), entry.line})
+	}
+
+	return json.Marshal(map[string][]stream{"streams": {s}})
+}
+
+// encodeLokiProto builds logproto.PushRequest by hand to avoid pulling in Loki itself.
+//
+//	PushRequest  { repeated StreamAdapter streams = 1; }
+//	StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	EntryAdapter  { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+func encodeLokiProto(labels map[string]string, batch []lokiEntry) []byte {
+	var stream []byte
+
+	stream = protowire.AppendTag(stream, 1, protowire.BytesType)
+	stream = protowire.AppendString(stream, formatLokiLabels(labels))
+
+	for _, entry := range batch {
+		var ts []byte
+		ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(entry.ts.Unix()))
+		ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(entry.ts.Nanosecond()))
+
+		var e []byte
+		e = protowire.AppendTag(e, 1, protowire.BytesType)
+		e = protowire.AppendBytes(e, ts)
+		e = protowire.AppendTag(e, 2, protowire.BytesType)
+		e = protowire.AppendString(e, entry.line)
+
+		stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+		stream = protowire.AppendBytes(stream, e)
+	}
+
+	var req []byte
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	req = protowire.AppendBytes(req, stream)
+
+	return req
+}
+
+// formatLokiLabels formats labels in the Prometheus selector form: {env="prod", service="api"}
+func formatLokiLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, strconv.Quote(labels[k])))
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func lokiURL(base string) string {
+	base = strings.TrimSuffix(base, "/")
+	if strings.HasSuffix(base, lokiPushPath) {
+		return base
+	}
+
+	return base + lokiPushPath
+}
+
+func valueOr[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+
+	return v
+}
@@ -0,0 +1,365 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"github.com/klauspost/compress/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type lokiPush struct {
+	labels string
+	lines  []string
+	times  []time.Time
+}
+
+// lokiStandIn is a local Loki replacement answering with the configured status codes in order
+type lokiStandIn struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	requests atomic.Int32
+	pushes   []lokiPush
+}
+
+func newLokiStandIn(t *testing.T, statuses ...int) (*lokiStandIn, *httptest.Server) {
+	l := &lokiStandIn{t: t, statuses: statuses}
+	srv := httptest.NewServer(l)
+	t.Cleanup(srv.Close)
+
+	return l, srv
+}
+
+func (l *lokiStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(l.requests.Add(1)) - 1
+
+	if r.URL.Path != lokiPushPath {
+		l.t.Errorf("path = %q, want %q", r.URL.Path, lokiPushPath)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n < len(l.statuses) && l.statuses[n] != http.StatusNoContent {
+		w.WriteHeader(l.statuses[n])
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		l.t.Errorf("read body: %v", err)
+	}
+
+	switch r.Header.Get("Content-Type") {
+	case "application/x-protobuf":
+		body, err = snappy.Decode(nil, body)
+		if err != nil {
+			l.t.Errorf("snappy decode: %v", err)
+		}
+
+		l.pushes = append(l.pushes, decodeLokiProto(l.t, body))
+	case "application/json":
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err == nil {
+				body, err = io.ReadAll(zr)
+			}
+
+			if err != nil {
+				l.t.Errorf("gzip decode: %v", err)
+			}
+		}
+
+		l.pushes = append(l.pushes, decodeLokiJSON(l.t, body))
+	default:
+		l.t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (l *lokiStandIn) received() []lokiPush {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]lokiPush(nil), l.pushes...)
+}
+
+func decodeLokiJSON(t *testing.T, body []byte) lokiPush {
+	var req struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("decode json: %v, body %s", err, body)
+	}
+
+	if len(req.Streams) != 1 {
+		t.Fatalf("streams = %d, want 1", len(req.Streams))
+	}
+
+	push := lokiPush{labels: formatLokiLabels(req.Streams[0].Stream)}
+	for _, v := range req.Streams[0].Values {
+		push.lines = append(push.lines, v[1])
+	}
+
+	return push
+}
+
+// decodeLokiProto decodes logproto.PushRequest with a single stream
+func decodeLokiProto(t *testing.T, body []byte) lokiPush {
+	var push lokiPush
+
+	stream := protoField(t, body, 1)
+
+	for len(stream) > 0 {
+		num, typ, n := protowire.ConsumeTag(stream)
+		if n < 0 || typ != protowire.BytesType {
+			t.Fatalf("bad stream tag")
+		}
+
+		stream = stream[n:]
+
+		value, n := protowire.ConsumeBytes(stream)
+		if n < 0 {
+			t.Fatalf("bad stream field %d", num)
+		}
+
+		stream = stream[n:]
+
+		switch num {
+		case 1:
+			push.labels = string(value)
+		case 2:
+			ts := protoField(t, value, 1)
+			push.lines = append(push.lines, string(protoField(t, value, 2)))
+			push.times = append(push.times, decodeTimestamp(t, ts))
+		}
+	}
+
+	return push
+}
+
+// protoField returns the first length-delimited field with the number
+func protoField(t *testing.T, b []byte, field protowire.Number) []byte {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("bad tag")
+		}
+
+		b = b[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			t.Fatalf("bad field %d", num)
+		}
+
+		if num == field && typ == protowire.BytesType {
+			value, _ := protowire.ConsumeBytes(b)
+			return value
+		}
+
+		b = b[n:]
+	}
+
+	t.Fatalf("field %d not found", field)
+
+	return nil
+}
+
+func decodeTimestamp(t *testing.T, b []byte) time.Time {
+	var sec, nsec uint64
+
+	for len(b) > 0 {
+		num, _, n := protowire.ConsumeTag(b)
+		b = b[n:]
+
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			t.Fatalf("bad timestamp")
+		}
+
+		b = b[n:]
+
+		switch num {
+		case 1:
+			sec = v
+		case 2:
+			nsec = v
+		}
+	}
+
+	return time.Unix(int64(sec), int64(nsec))
+}
+
+func newTestLokiHandler(url, compression string) *LokiHandler {
+	return NewLokiHandler(&LokiConfig{
+		URL:         url,
+		Labels:      map[string]string{"service": "api", "env": "test"},
+		Compression: compression,
+		BatchWait:   10 * time.Millisecond,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		MaxRetries:  3,
+		Registry:    metrics.NewRegistry(),
+	})
+}
+
+func TestLokiHandlerPush(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression string
+	}{
+		{name: "json", compression: CompressionNone},
+		{name: "gzip", compression: CompressionGzip},
+		{name: "snappy", compression: CompressionSnappy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loki, srv := newLokiStandIn(t)
+
+			h := newTestLokiHandler(srv.URL, tt.compression)
+			log := slog.New(h)
+
+			before := time.Now()
+
+			log.Info("first", slog.String("user", "42"))
+			log.Warn("second")
+
+			if err := h.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			pushes := loki.received()
+			if len(pushes) != 1 {
+				t.Fatalf("pushes = %d, want 1", len(pushes))
+			}
+
+			push := pushes[0]
+
+			if want := `{env="test", service="api"}`; push.labels != want {
+				t.Errorf("labels = %s, want %s", push.labels, want)
+			}
+
+			if len(push.lines) != 2 {
+				t.Fatalf("lines = %d, want 2", len(push.lines))
+			}
+
+			var first map[string]any
+			if err := json.Unmarshal([]byte(push.lines[0]), &first); err != nil {
+				t.Fatalf("line is not JSON: %v", err)
+			}
+
+			if first["msg"] != "first" || first["user"] != "42" {
+				t.Errorf("first line = %s", push.lines[0])
+			}
+
+			for _, ts := range push.times {
+				if ts.Before(before) || ts.After(time.Now()) {
+					t.Errorf("timestamp %s out of range", ts)
+				}
+			}
+
+			if h.Dropped() != 0 {
+				t.Errorf("dropped = %d, want 0", h.Dropped())
+			}
+		})
+	}
+}
+
+func TestLokiHandlerRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int32
+		pushed   int
+		dropped  uint64
+	}{
+		{name: "server error", statuses: []int{500, 503}, requests: 3, pushed: 1},
+		{name: "too many requests", statuses: []int{429}, requests: 2, pushed: 1},
+		{name: "bad request", statuses: []int{400}, requests: 1, dropped: 1},
+		{name: "retries exhausted", statuses: []int{500, 500, 500, 500}, requests: 4, dropped: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loki, srv := newLokiStandIn(t, tt.statuses...)
+
+			h := newTestLokiHandler(srv.URL, CompressionSnappy)
+			slog.New(h).Info("retried")
+
+			if err := h.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			if got := loki.requests.Load(); got != tt.requests {
+				t.Errorf("requests = %d, want %d", got, tt.requests)
+			}
+
+			if got := len(loki.received()); got != tt.pushed {
+				t.Errorf("pushes = %d, want %d", got, tt.pushed)
+			}
+
+			if got := h.Dropped(); got != tt.dropped {
+				t.Errorf("dropped = %d, want %d", got, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestLokiHandlerDefaultLabels(t *testing.T) {
+	loki, srv := newLokiStandIn(t)
+
+	h := NewLokiHandler(&LokiConfig{URL: srv.URL, Registry: metrics.NewRegistry()})
+	slog.New(h).Info("unlabelled")
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	pushes := loki.received()
+	if len(pushes) != 1 || pushes[0].labels != `{service="unknown"}` {
+		t.Fatalf("pushes = %+v", pushes)
+	}
+}
+
+func TestLokiHandlerShutdownTwice(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	h := newTestLokiHandler(srv.URL, CompressionNone)
+	slog.New(h).Info("stuck")
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+
+		if err := h.Shutdown(ctx); err == nil {
+			t.Errorf("shutdown %d: expected deadline error", i)
+		}
+
+		cancel()
+	}
+}
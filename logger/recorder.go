@@ -0,0 +1,249 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TestingT is the part of testing.TB used by the recording handler
+type TestingT interface {
+	Helper()
+	Log(args ...any)
+}
+
+// CapturedRecord is a record stored by RecordingHandler.
+// Attrs are flattened, attributes inside groups are keyed as "group.key".
+type CapturedRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]slog.Value
+}
+
+// Attr returns the value of the attribute
+func (r CapturedRecord) Attr(key string) (slog.Value, bool) {
+	v, ok := r.Attrs[key]
+	return v, ok
+}
+
+// HasAttr reports whether the record has the attribute with the value
+func (r CapturedRecord) HasAttr(key string, value any) bool {
+	v, ok := r.Attrs[key]
+	return ok && v.Equal(slog.AnyValue(value).Resolve())
+}
+
+func (r CapturedRecord) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s %s", r.Level, r.Message)
+
+	keys := make([]string, 0, len(r.Attrs))
+	for k := range r.Attrs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, r.Attrs[k])
+	}
+
+	return b.String()
+}
+
+type recordStore struct {
+	mu      sync.Mutex
+	records []CapturedRecord
+}
+
+// RecordingHandler keeps records in memory, used for testing
+type RecordingHandler struct {
+	store  *recordStore
+	attrs  map[string]slog.Value
+	groups []string
+
+	t       TestingT
+	forward bool
+}
+
+func NewRecordingHandler() *RecordingHandler {
+	return &RecordingHandler{
+		store: &recordStore{},
+		attrs: map[string]slog.Value{},
+	}
+}
+
+type TestOption func(*RecordingHandler)
+
+// ForwardToT passes every captured record to t.Log, so failed tests show the log context
+func ForwardToT() TestOption {
+	return func(h *RecordingHandler) {
+		h.forward = true
+	}
+}
+
+// NewTest creates logger capturing every record, returned handler is used to query them
+func NewTest(t TestingT, opts ...TestOption) (*Logger, *RecordingHandler) {
+	t.Helper()
+
+	h := NewRecordingHandler()
+	h.t = t
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	levels := NewLevelRegistry(minLevel)
+
+	return &Logger{
+		l:      slog.New(NewContextHandler(NewLevelHandler(h, levels))),
+		levels: levels,
+	}, h
+}
+
+func (h *RecordingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (h *RecordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	captured := CapturedRecord{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   make(map[string]slog.Value, len(h.attrs)+record.NumAttrs()),
+	}
+
+	for k, v := range h.attrs {
+		captured.Attrs[k] = v
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		flattenAttr(captured.Attrs, h.groups, attr)
+		return true
+	})
+
+	h.store.mu.Lock()
+	h.store.records = append(h.store.records, captured)
+	h.store.mu.Unlock()
+
+	if h.forward && h.t != nil {
+		h.t.Helper()
+		h.t.Log(captured.String())
+	}
+
+	return nil
+}
+
+func (h *RecordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := h.clone()
+
+	for _, attr := range attrs {
+		flattenAttr(c.attrs, c.groups, attr)
+	}
+
+	return c
+}
+
+func (h *RecordingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	c := h.clone()
+	c.groups = append(c.groups, name)
+
+	return c
+}
+
+func (h *RecordingHandler) clone() *RecordingHandler {
+	c := *h
+
+	c.attrs = make(map[string]slog.Value, len(h.attrs))
+	for k, v := range h.attrs {
+		c.attrs[k] = v
+	}
+
+	c.groups = append([]string(nil), h.groups...)
+
+	return &c
+}
+
+// Records returns all captured records
+func (h *RecordingHandler) Records() []CapturedRecord {
+	h.store.mu.Lock()
+	defer h.store.mu.Unlock()
+
+	return append([]CapturedRecord(nil), h.store.records...)
+}
+
+// Reset removes all captured records
+func (h *RecordingHandler) Reset() {
+	h.store.mu.Lock()
+	h.store.records = nil
+	h.store.mu.Unlock()
+}
+
+// ByLevel returns records of the level
+func (h *RecordingHandler) ByLevel(level slog.Level) []CapturedRecord {
+	return h.filter(func(r CapturedRecord) bool {
+		return r.Level == level
+	})
+}
+
+// ByMessage returns records with the message
+func (h *RecordingHandler) ByMessage(msg string) []CapturedRecord {
+	return h.filter(func(r CapturedRecord) bool {
+		return r.Message == msg
+	})
+}
+
+// ByAttr returns records having the attribute with the value
+func (h *RecordingHandler) ByAttr(key string, value any) []CapturedRecord {
+	return h.filter(func(r CapturedRecord) bool {
+		return r.HasAttr(key, value)
+	})
+}
+
+// Contains reports whether a record with the message was captured
+func (h *RecordingHandler) Contains(msg string) bool {
+	return len(h.ByMessage(msg)) > 0
+}
+
+func (h *RecordingHandler) filter(match func(CapturedRecord) bool) []CapturedRecord {
+	var matched []CapturedRecord
+
+	for _, r := range h.Records() {
+		if match(r) {
+			matched = append(matched, r)
+		}
+	}
+
+	return matched
+}
+
+func flattenAttr(dst map[string]slog.Value, groups []string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], attr.Key)
+		}
+
+		for _, a := range attr.Value.Group() {
+			flattenAttr(dst, groups, a)
+		}
+
+		return
+	}
+
+	if attr.Key == "" {
+		return
+	}
+
+	dst[strings.Join(append(groups[:len(groups):len(groups)], attr.Key), ".")] = attr.Value
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bigMessage struct {
+	Payload string
+}
+
+func (m *bigMessage) String() string {
+	return "payload:" + m.Payload
+}
+
+func TestRedactHandlerTruncation(t *testing.T) {
+	long := strings.Repeat("x", 64)
+
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want string
+	}{
+		{name: "string", attr: slog.String("body", long), want: strings.Repeat("x", 16) + truncatedSuffix},
+		{name: "stringer", attr: slog.Any("reply", &bigMessage{Payload: long}), want: "payload:" + strings.Repeat("x", 8) + truncatedSuffix},
+		{name: "map", attr: slog.Any("value", map[string]string{"k": long}), want: `{"k":"` + strings.Repeat("x", 10) + truncatedSuffix},
+		{name: "short any", attr: slog.Any("value", []int{1, 2}), want: "[1 2]"},
+		{name: "sensitive", attr: slog.Any("token", long), want: redactedValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := NewRecordingHandler()
+			h := NewRedactHandler(rec, &RedactOptions{Keys: DefaultRedactKeys, MaxValueLen: 16})
+
+			record := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+			record.AddAttrs(tt.attr)
+
+			if err := h.Handle(context.Background(), record); err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := rec.Records()[0].Attr(tt.attr.Key)
+			if !ok {
+				t.Fatalf("attribute %q missing", tt.attr.Key)
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("%s = %q, want %q", tt.attr.Key, got.String(), tt.want)
+			}
+		})
+	}
+}
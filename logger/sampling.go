@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	samplingBuckets = 4096
+
+	defaultSamplingInterval   = time.Second
+	defaultSamplingThereafter = 100
+)
+
+// SamplingOptions configures the sampling handler.
+// Within every interval the first First records with the same level and message
+// are logged, after that only every Thereafter-th record is logged.
+// Zero Thereafter drops every record after the first ones until the interval ends.
+type SamplingOptions struct {
+	Interval   time.Duration
+	First      uint64
+	Thereafter uint64
+
+	// Registry counts dropped records, metrics.Default when nil
+	Registry *metrics.Registry
+}
+
+// SamplingHandler drops repeated records on hot paths. Errors are always kept.
+type SamplingHandler struct {
+	handler  slog.Handler
+	opts     SamplingOptions
+	counters *samplingCounters
+	dropped  *prometheus.CounterVec
+	now      func() time.Time
+}
+
+func NewSamplingHandler(handler slog.Handler, opts *SamplingOptions) *SamplingHandler {
+	o := *opts
+	if o.Interval <= 0 {
+		o.Interval = defaultSamplingInterval
+	}
+
+	if o.Registry == nil {
+		o.Registry = metrics.Default()
+	}
+
+	return &SamplingHandler{
+		handler:  handler,
+		opts:     o,
+		counters: &samplingCounters{},
+		dropped:  o.Registry.LogRecordsDropped(),
+		now:      time.Now,
+	}
+}
+
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError || h.sample(record) {
+		return h.handler.Handle(ctx, record)
+	}
+
+	h.dropped.WithLabelValues(record.Level.String()).Inc()
+
+	return nil
+}
+
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{
+		handler:  h.handler.WithAttrs(attrs),
+		opts:     h.opts,
+		counters: h.counters,
+		dropped:  h.dropped,
+		now:      h.now,
+	}
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{
+		handler:  h.handler.WithGroup(name),
+		opts:     h.opts,
+		counters: h.counters,
+		dropped:  h.dropped,
+		now:      h.now,
+	}
+}
+
+func (h *SamplingHandler) sample(record slog.Record) bool {
+	n := h.counters.get(record.Level, record.Message).inc(h.now(), h.opts.Interval)
+
+	if n <= h.opts.First {
+		return true
+	}
+
+	return h.opts.Thereafter > 0 && (n-h.opts.First)%h.opts.Thereafter == 0
+}
+
+// samplingCounters is a fixed set of counters indexed by hash of level and message,
+// so memory stays bounded no matter how many distinct messages are logged.
+type samplingCounters [samplingBuckets]samplingCounter
+
+func (c *samplingCounters) get(level slog.Level, msg string) *samplingCounter {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(level.String()))
+	_, _ = h.Write([]byte(msg))
+
+	return &c[h.Sum32()%samplingBuckets]
+}
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// inc increments the counter and returns its value within the current interval
+func (c *samplingCounter) inc(now time.Time, interval time.Duration) uint64 {
+	tn := now.UnixNano()
+
+	resetAt := c.resetAt.Load()
+	if resetAt > tn {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+
+	if !c.resetAt.CompareAndSwap(resetAt, tn+interval.Nanoseconds()) {
+		// another goroutine has already started the new interval
+		return c.count.Add(1)
+	}
+
+	return 1
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	type batch struct {
+		at    time.Duration
+		level slog.Level
+		msg   string
+		count int
+		kept  int
+	}
+
+	tests := []struct {
+		name    string
+		opts    SamplingOptions
+		batches []batch
+		dropped map[slog.Level]float64
+	}{
+		{
+			name:    "first records of the interval",
+			opts:    SamplingOptions{First: 3},
+			batches: []batch{{level: slog.LevelInfo, msg: "cache miss", count: 5, kept: 3}},
+			dropped: map[slog.Level]float64{slog.LevelInfo: 2},
+		},
+		{
+			name: "every thereafter-th record after the first ones",
+			opts: SamplingOptions{First: 2, Thereafter: 3},
+			// records 1, 2, 5 and 8 are kept
+			batches: []batch{{level: slog.LevelDebug, msg: "cache miss", count: 10, kept: 4}},
+			dropped: map[slog.Level]float64{slog.LevelDebug: 6},
+		},
+		{
+			name:    "errors are always kept",
+			opts:    SamplingOptions{First: 1},
+			batches: []batch{{level: slog.LevelError, msg: "query failed", count: 5, kept: 5}},
+			dropped: map[slog.Level]float64{slog.LevelError: 0},
+		},
+		{
+			name: "interval reset",
+			opts: SamplingOptions{Interval: time.Second, First: 2},
+			batches: []batch{
+				{level: slog.LevelInfo, msg: "cache miss", count: 4, kept: 2},
+				{at: 500 * time.Millisecond, level: slog.LevelInfo, msg: "cache miss", count: 2, kept: 0},
+				{at: time.Second, level: slog.LevelInfo, msg: "cache miss", count: 4, kept: 2},
+			},
+			dropped: map[slog.Level]float64{slog.LevelInfo: 6},
+		},
+		{
+			name: "default interval",
+			opts: SamplingOptions{First: 1},
+			batches: []batch{
+				{level: slog.LevelInfo, msg: "cache miss", count: 2, kept: 1},
+				{at: defaultSamplingInterval, level: slog.LevelInfo, msg: "cache miss", count: 2, kept: 1},
+			},
+			dropped: map[slog.Level]float64{slog.LevelInfo: 2},
+		},
+		{
+			name: "messages and levels are counted separately",
+			opts: SamplingOptions{First: 1},
+			batches: []batch{
+				{level: slog.LevelInfo, msg: "cache miss", count: 3, kept: 1},
+				{level: slog.LevelInfo, msg: "cache hit", count: 3, kept: 1},
+				{level: slog.LevelWarn, msg: "cache miss", count: 3, kept: 1},
+			},
+			dropped: map[slog.Level]float64{slog.LevelInfo: 4, slog.LevelWarn: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			now := start
+
+			rec := NewRecordingHandler()
+			opts := tt.opts
+			opts.Registry = metrics.NewRegistry()
+
+			h := NewSamplingHandler(rec, &opts)
+			h.now = func() time.Time { return now }
+
+			// records go through a derived handler to check the counters are shared
+			log := slog.New(h.WithAttrs([]slog.Attr{slog.String("component", "cache")}))
+
+			for _, b := range tt.batches {
+				now = start.Add(b.at)
+				rec.Reset()
+
+				for range b.count {
+					log.Log(context.Background(), b.level, b.msg)
+				}
+
+				if got := len(rec.Records()); got != b.kept {
+					t.Errorf("%s %q at %s: kept %d, want %d", b.level, b.msg, b.at, got, b.kept)
+				}
+			}
+
+			for level, want := range tt.dropped {
+				if got := testutil.ToFloat64(h.dropped.WithLabelValues(level.String())); got != want {
+					t.Errorf("dropped %s = %v, want %v", level, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSamplingOptionsFromEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      string
+		thereafter string
+		want       *SamplingOptions
+	}{
+		{name: "off", want: nil},
+		{name: "default thereafter", first: "10", want: &SamplingOptions{First: 10, Thereafter: defaultSamplingThereafter}},
+		{name: "thereafter", first: "10", thereafter: "5", want: &SamplingOptions{First: 10, Thereafter: 5}},
+		{name: "explicit zero thereafter", first: "10", thereafter: "0", want: &SamplingOptions{First: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOG_SAMPLING_FIRST", tt.first)
+			t.Setenv("LOG_SAMPLING_THEREAFTER", tt.thereafter)
+			t.Setenv("LOG_SAMPLING_INTERVAL", "")
+
+			got := samplingOptions()
+
+			switch {
+			case got == nil || tt.want == nil:
+				if got != tt.want {
+					t.Errorf("options = %+v, want %+v", got, tt.want)
+				}
+			case *got != *tt.want:
+				t.Errorf("options = %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
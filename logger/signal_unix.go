@@ -0,0 +1,39 @@
+//go:build !windows
+
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NotifySignals switches every component to debug on SIGUSR1
+// and restores the initial levels on SIGUSR2. Call stop to unsubscribe.
+func (r *LevelRegistry) NotifySignals() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				r.ResetAll()
+
+				if sig == syscall.SIGUSR1 {
+					r.SetLevel("", slog.LevelDebug)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
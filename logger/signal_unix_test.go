@@ -0,0 +1,48 @@
+//go:build !windows
+
+package logger
+
+import (
+	"log/slog"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNotifySignals(t *testing.T) {
+	t.Setenv("LOG_SIGNALS", "true")
+	t.Setenv("LOKI_URL", "")
+
+	log := New()
+	t.Cleanup(func() { _ = log.Close() })
+
+	levels := log.Levels()
+	levels.SetLevel("", slog.LevelWarn)
+	levels.SetLevel("postgres", slog.LevelError)
+
+	waitLevel := func(component string, want slog.Level) {
+		t.Helper()
+
+		deadline := time.Now().Add(time.Second)
+		for levels.Level(component) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("level of %q = %s, want %s", component, levels.Level(component), want)
+			}
+
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("send SIGUSR1: %v", err)
+	}
+
+	waitLevel("", slog.LevelDebug)
+	waitLevel("postgres", slog.LevelDebug)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatalf("send SIGUSR2: %v", err)
+	}
+
+	waitLevel("", configLevel())
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package logger
+
+// NotifySignals is a no-op on windows which has no SIGUSR1/SIGUSR2
+func (r *LevelRegistry) NotifySignals() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// DefaultGRPCDurationBuckets are RPC handling duration buckets in seconds
+var DefaultGRPCDurationBuckets = prometheus.DefBuckets
+
+var (
+	grpcLabels     = []string{"grpc_type", "grpc_service", "grpc_method"}
+	grpcCodeLabels = []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}
+)
+
+// GRPCMetrics are RED metrics of the gRPC server or client
+type GRPCMetrics struct {
+	StartedTotal     *prometheus.CounterVec
+	HandledTotal     *prometheus.CounterVec
+	HandlingSeconds  *prometheus.HistogramVec
+	MsgReceivedTotal *prometheus.CounterVec
+	MsgSentTotal     *prometheus.CounterVec
+}
+
+// NewGRPCServerMetrics creates gRPC server metrics and registers them in reg
+func NewGRPCServerMetrics(reg prometheus.Registerer) *GRPCMetrics {
+	return newGRPCMetrics(reg, "server", "completed by the server")
+}
+
+// NewGRPCClientMetrics creates gRPC client metrics and registers them in reg
+func NewGRPCClientMetrics(reg prometheus.Registerer) *GRPCMetrics {
+	return newGRPCMetrics(reg, "client", "completed by the client")
+}
+
+// NewGRPCServerPanicsTotal creates counter of panics recovered in gRPC handlers and registers it in reg
+func NewGRPCServerPanicsTotal(reg prometheus.Registerer) *prometheus.CounterVec {
+	return register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_panics_total",
+		Help: "Total number of panics recovered in RPC handlers",
+	}, grpcLabels))
+}
+
+func newGRPCMetrics(reg prometheus.Registerer, side, completed string) *GRPCMetrics {
+	return &GRPCMetrics{
+		StartedTotal: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_" + side + "_started_total",
+			Help: "Total number of RPCs started on the " + side,
+		}, grpcLabels)),
+		HandledTotal: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_" + side + "_handled_total",
+			Help: "Total number of RPCs " + completed + ", regardless of success or failure",
+		}, grpcCodeLabels)),
+		HandlingSeconds: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_" + side + "_handling_seconds",
+			Help:    "Duration of RPCs " + completed + " in seconds",
+			Buckets: DefaultGRPCDurationBuckets,
+		}, grpcLabels)),
+		MsgReceivedTotal: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_" + side + "_msg_received_total",
+			Help: "Total number of RPC stream messages received on the " + side,
+		}, grpcLabels)),
+		MsgSentTotal: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_" + side + "_msg_sent_total",
+			Help: "Total number of RPC stream messages sent by the " + side,
+		}, grpcLabels)),
+	}
+}
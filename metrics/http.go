@@ -0,0 +1,67 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// DefaultHTTPDurationBuckets are request duration buckets in seconds
+var DefaultHTTPDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultHTTPSizeBuckets are request and response size buckets in bytes, from 100B to 100MB
+var DefaultHTTPSizeBuckets = prometheus.ExponentialBuckets(100, 10, 7)
+
+var httpLabels = []string{"route", "method", "status"}
+
+// HTTPMetricsOpts configures buckets of the HTTP histograms, defaults are used for empty buckets
+type HTTPMetricsOpts struct {
+	DurationBuckets []float64
+	SizeBuckets     []float64
+}
+
+// HTTPMetrics are RED metrics of the HTTP server labelled by route template, method and status code
+type HTTPMetrics struct {
+	RequestsTotal   *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+	RequestSize     *prometheus.HistogramVec
+	ResponseSize    *prometheus.HistogramVec
+	InFlight        *prometheus.GaugeVec
+}
+
+// NewHTTPMetrics creates HTTP metrics and registers them in reg,
+// collectors already registered by another router are reused
+func NewHTTPMetrics(reg prometheus.Registerer, opts *HTTPMetricsOpts) *HTTPMetrics {
+	durationBuckets := DefaultHTTPDurationBuckets
+	sizeBuckets := DefaultHTTPSizeBuckets
+
+	if opts != nil && len(opts.DurationBuckets) > 0 {
+		durationBuckets = opts.DurationBuckets
+	}
+
+	if opts != nil && len(opts.SizeBuckets) > 0 {
+		sizeBuckets = opts.SizeBuckets
+	}
+
+	return &HTTPMetrics{
+		RequestsTotal: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests",
+		}, httpLabels)),
+		RequestDuration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests in seconds",
+			Buckets: durationBuckets,
+		}, httpLabels)),
+		RequestSize: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_size_bytes",
+			Help:    "Size of HTTP request bodies in bytes",
+			Buckets: sizeBuckets,
+		}, httpLabels)),
+		ResponseSize: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of HTTP response bodies in bytes",
+			Buckets: sizeBuckets,
+		}, httpLabels)),
+		InFlight: register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being served",
+		}, []string{"route", "method"})),
+	}
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// DefaultPostgresDurationBuckets are query duration buckets in seconds
+var DefaultPostgresDurationBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+var postgresLabels = []string{"database", "statement"}
+
+// PostgresMetrics are query metrics labelled by database and statement name
+type PostgresMetrics struct {
+	QueryDuration *prometheus.HistogramVec
+	QueryErrors   *prometheus.CounterVec
+}
+
+// NewPostgresMetrics creates Postgres query metrics and registers them in reg
+func NewPostgresMetrics(reg prometheus.Registerer) *PostgresMetrics {
+	return &PostgresMetrics{
+		QueryDuration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "postgres_query_duration_seconds",
+			Help:    "Duration of Postgres queries in seconds",
+			Buckets: DefaultPostgresDurationBuckets,
+		}, postgresLabels)),
+		QueryErrors: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "postgres_query_errors_total",
+			Help: "Total number of failed Postgres queries",
+		}, postgresLabels)),
+	}
+}
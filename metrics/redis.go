@@ -0,0 +1,42 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// DefaultRedisDurationBuckets are command duration buckets in seconds
+var DefaultRedisDurationBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+var (
+	redisLabels        = []string{"addr", "db"}
+	redisCommandLabels = []string{"addr", "db", "command"}
+)
+
+// RedisMetrics are command and cache metrics labelled by redis address and database
+type RedisMetrics struct {
+	CommandDuration *prometheus.HistogramVec
+	CommandErrors   *prometheus.CounterVec
+	CacheHits       *prometheus.CounterVec
+	CacheMisses     *prometheus.CounterVec
+}
+
+// NewRedisMetrics creates redis metrics and registers them in reg
+func NewRedisMetrics(reg prometheus.Registerer) *RedisMetrics {
+	return &RedisMetrics{
+		CommandDuration: register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Duration of redis commands in seconds",
+			Buckets: DefaultRedisDurationBuckets,
+		}, redisCommandLabels)),
+		CommandErrors: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_command_errors_total",
+			Help: "Total number of failed redis commands",
+		}, redisCommandLabels)),
+		CacheHits: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_cache_hits_total",
+			Help: "Total number of Get calls which found the key",
+		}, redisLabels)),
+		CacheMisses: register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_cache_misses_total",
+			Help: "Total number of Get calls which did not find the key",
+		}, redisLabels)),
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry owns collectors of a service. Collectors are registered on first use,
+// asking for the same metrics twice returns the already registered collectors.
+type Registry struct {
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+
+	constLabels prometheus.Labels
+}
+
+type Option func(*Registry)
+
+// ConstLabels attaches labels to every metric of the registry, e.g. service and version
+func ConstLabels(labels map[string]string) Option {
+	return func(r *Registry) {
+		for k, v := range labels {
+			r.constLabels[k] = v
+		}
+	}
+}
+
+// NewRegistry creates registry with Go runtime and process collectors
+func NewRegistry(opts ...Option) *Registry {
+	reg := prometheus.NewRegistry()
+
+	r := &Registry{
+		registerer:  reg,
+		gatherer:    reg,
+		constLabels: prometheus.Labels{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if len(r.constLabels) > 0 {
+		r.registerer = prometheus.WrapRegistererWith(r.constLabels, reg)
+	}
+
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return r
+}
+
+var defaultRegistry = &Registry{
+	registerer: prometheus.DefaultRegisterer,
+	gatherer:   prometheus.DefaultGatherer,
+}
+
+// Default returns registry backed by the global prometheus registry,
+// it is used when no registry is passed
+func Default() *Registry {
+	return defaultRegistry
+}
+
+func (r *Registry) Registerer() prometheus.Registerer {
+	return r.registerer
+}
+
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	return r.gatherer
+}
+
+func (r *Registry) Register(c prometheus.Collector) error {
+	return r.registerer.Register(c)
+}
+
+func (r *Registry) Unregister(c prometheus.Collector) bool {
+	return r.registerer.Unregister(c)
+}
+
+func (r *Registry) MustRegister(cs ...prometheus.Collector) {
+	r.registerer.MustRegister(cs...)
+}
+
+// Handler serves metrics of the registry
+func (r *Registry) Handler() http.Handler {
+	return promhttp.InstrumentMetricHandler(r.registerer, promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{}))
+}
+
+// HTTPMetrics returns metrics of the HTTP server
+func (r *Registry) HTTPMetrics(opts *HTTPMetricsOpts) *HTTPMetrics {
+	return NewHTTPMetrics(r.registerer, opts)
+}
+
+// GRPCServerMetrics returns metrics of the gRPC server
+func (r *Registry) GRPCServerMetrics() *GRPCMetrics {
+	return NewGRPCServerMetrics(r.registerer)
+}
+
+// GRPCClientMetrics returns metrics of the gRPC client
+func (r *Registry) GRPCClientMetrics() *GRPCMetrics {
+	return NewGRPCClientMetrics(r.registerer)
+}
+
+// GRPCServerPanicsTotal returns counter of panics recovered in gRPC handlers
+func (r *Registry) GRPCServerPanicsTotal() *prometheus.CounterVec {
+	return NewGRPCServerPanicsTotal(r.registerer)
+}
+
+// PostgresMetrics returns metrics of Postgres queries
+func (r *Registry) PostgresMetrics() *PostgresMetrics {
+	return NewPostgresMetrics(r.registerer)
+}
+
+// RedisMetrics returns metrics of redis commands
+func (r *Registry) RedisMetrics() *RedisMetrics {
+	return NewRedisMetrics(r.registerer)
+}
+
+// LogRecordsDropped returns counter of log records dropped by sampling
+func (r *Registry) LogRecordsDropped() *prometheus.CounterVec {
+	return register(r.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_records_dropped_total",
+		Help: "Total number of log records dropped by sampling",
+	}, []string{"level"}))
+}
+
+// LokiRecordsDropped returns counter of log records the Loki handler failed to deliver
+func (r *Registry) LokiRecordsDropped() *prometheus.CounterVec {
+	return register(r.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "loki_records_dropped_total",
+		Help: "Total number of log records dropped by the Loki handler",
+	}, []string{"reason"}))
+}
@@ -7,13 +7,11 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/trace"
 )
 
 type Client struct {
 	log    *logger.Logger
-	tracer trace.Tracer
+	tracer *Tracer
 	client *minio.Client
 }
 
@@ -33,6 +31,6 @@ func NewClient(log *logger.Logger, cfg *Config) (*Client, error) {
 	return &Client{
 		log:    log,
 		client: minioClient,
-		tracer: otel.GetTracerProvider().Tracer("minio"),
+		tracer: setTracing(cfg),
 	}, nil
 }
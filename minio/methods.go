@@ -2,6 +2,7 @@ package minio
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/minio/minio-go/v7"
@@ -19,34 +20,55 @@ type RemoveObjectOptions struct {
 	BucketName string
 }
 
-func (c *Client) SaveObject(ctx context.Context, opts *SaveObjectOptions) error {
-	ctx, span := c.tracer.Start(ctx, "save-object")
-	defer span.End()
+func (c *Client) SaveObject(ctx context.Context, opts *SaveObjectOptions) (err error) {
+	ctx, span := c.tracer.start(ctx, "save-object", "PutObject", opts.BucketName, opts.FileName,
+		objectContentTypeKey.String(opts.ContentType),
+	)
+	defer func() { endSpan(span, err) }()
 
 	info, err := c.client.FPutObject(ctx, opts.BucketName, opts.FileName, opts.FilePath, minio.PutObjectOptions{
 		ContentType: opts.ContentType,
 	})
 	if err != nil {
-		c.log.Error("failed to upload object", err, slog.String("filename", opts.FileName))
+		c.log.ErrorContext(ctx, "failed to upload object", err, slog.String("filename", opts.FileName))
 		return err
 	}
 
-	c.log.Info("successfully uploaded object of size", slog.String("filename", opts.FileName), slog.Int64("size", info.Size))
+	span.SetAttributes(objectSizeKey.Int64(info.Size))
+
+	c.log.InfoContext(ctx, "successfully uploaded object of size", slog.String("filename", opts.FileName), slog.Int64("size", info.Size))
 
 	return nil
 }
 
-func (c *Client) RemoveObject(ctx context.Context, opts *RemoveObjectOptions) error {
-	ctx, span := c.tracer.Start(ctx, "remove-object")
-	defer span.End()
+func (c *Client) RemoveObject(ctx context.Context, opts *RemoveObjectOptions) (err error) {
+	ctx, span := c.tracer.start(ctx, "remove-object", "DeleteObject", opts.BucketName, opts.FileName)
+	defer func() { endSpan(span, err) }()
 
-	err := c.client.RemoveObject(ctx, opts.BucketName, opts.FileName, minio.RemoveObjectOptions{})
+	err = c.client.RemoveObject(ctx, opts.BucketName, opts.FileName, minio.RemoveObjectOptions{})
 	if err != nil {
-		c.log.Error("failed to remove object", err, slog.String("filename", opts.FileName))
+		c.log.ErrorContext(ctx, "failed to remove object", err, slog.String("filename", opts.FileName))
 		return err
 	}
 
-	c.log.Info("successfully removed object", slog.String("filename", opts.FileName))
+	c.log.InfoContext(ctx, "successfully removed object", slog.String("filename", opts.FileName))
+
+	return nil
+}
+
+// CheckBucket checks MinIO is reachable and the bucket exists, used by health checks
+func (c *Client) CheckBucket(ctx context.Context, bucketName string) (err error) {
+	ctx, span := c.tracer.start(ctx, "check-bucket", "HeadBucket", bucketName, "")
+	defer func() { endSpan(span, err) }()
+
+	exists, err := c.client.BucketExists(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("bucket %q does not exist", bucketName)
+	}
 
 	return nil
 }
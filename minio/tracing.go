@@ -1,17 +1,31 @@
 package minio
 
 import (
+	"context"
+	"strconv"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	tracerName = "github.com/TakeAway-Inc/platform"
+	tracerName  = "github.com/TakeAway-Inc/platform/minio"
+	serviceName = "minio"
 )
 
+// custom attributes, there are no semantic conventions for them
+const (
+	objectSizeKey        = attribute.Key("minio.object.size")
+	objectContentTypeKey = attribute.Key("minio.object.content_type")
+)
+
+// Tracer starts client spans of MinIO operations with the common attributes
 type Tracer struct {
 	tracer trace.Tracer
+	attrs  []attribute.KeyValue
 }
 
 type tracerConfig struct {
@@ -19,13 +33,54 @@ type tracerConfig struct {
 	attrs []attribute.KeyValue
 }
 
-func setTracing() (*Tracer, error) {
-	cfg := &tracerConfig{
+func setTracing(cfg *Config) *Tracer {
+	tc := &tracerConfig{
 		tp: otel.GetTracerProvider(),
 		attrs: []attribute.KeyValue{
-			attribute.Key("service.name").String("minio"),
+			semconv.PeerService(serviceName),
+			semconv.RPCSystemKey.String("aws-api"),
+			semconv.RPCService("S3"),
+			semconv.ServerAddress(cfg.Host),
 		},
 	}
 
-	return &Tracer{tracer: cfg.tp.Tracer(tracerName)}, nil
+	if port, err := strconv.Atoi(cfg.Port); err == nil {
+		tc.attrs = append(tc.attrs, semconv.ServerPort(port))
+	}
+
+	return &Tracer{
+		tracer: tc.tp.Tracer(tracerName),
+		attrs:  tc.attrs,
+	}
+}
+
+// start starts span of the operation, method is the S3 API call, e.g. PutObject, object is empty for bucket operations
+func (t *Tracer) start(ctx context.Context, name, method, bucket, object string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	spanAttrs := make([]attribute.KeyValue, 0, len(t.attrs)+len(attrs)+3)
+	spanAttrs = append(spanAttrs, t.attrs...)
+	spanAttrs = append(spanAttrs,
+		semconv.RPCMethod(method),
+		semconv.AWSS3Bucket(bucket),
+	)
+
+	if object != "" {
+		spanAttrs = append(spanAttrs, semconv.AWSS3Key(object))
+	}
+
+	spanAttrs = append(spanAttrs, attrs...)
+
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttrs...),
+	)
+}
+
+// endSpan records the error of the operation and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
 }
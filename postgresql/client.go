@@ -4,25 +4,28 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"time"
 
 	"github.com/TakeAway-Inc/platform/logger"
+	"github.com/TakeAway-Inc/platform/metrics"
 
 	"github.com/Masterminds/squirrel"
-	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
-	defaultMaxPoolSize       = 1
-	defaultMinPoolSize       = 1
-	defaultConnAttempts      = 10
-	defaultConnTimeout       = time.Second
-	defaultConnLifetime      = time.Minute
-	defaultIdleTime          = time.Minute
-	defaultHealthCheckPeriod = time.Minute
+	defaultMaxPoolSize        = 1
+	defaultMinPoolSize        = 1
+	defaultConnAttempts       = 10
+	defaultConnTimeout        = time.Second
+	defaultConnLifetime       = time.Minute
+	defaultIdleTime           = time.Minute
+	defaultHealthCheckPeriod  = time.Minute
+	defaultSlowQueryThreshold = time.Second
+	defaultPoolName           = "default"
 )
 
 type PgxPool interface {
@@ -40,13 +43,20 @@ type PgxPool interface {
 }
 
 type Postgres struct {
-	maxPoolSize       int
-	minPoolSize       int
-	connAttempts      int
-	maxConnLifetime   time.Duration
-	maxConnIdleTime   time.Duration
-	healthCheckPeriod time.Duration
-	connTimeout       time.Duration
+	maxPoolSize        int
+	minPoolSize        int
+	connAttempts       int
+	maxConnLifetime    time.Duration
+	maxConnIdleTime    time.Duration
+	healthCheckPeriod  time.Duration
+	connTimeout        time.Duration
+	slowQueryThreshold time.Duration
+
+	database  string
+	host      string
+	poolName  string
+	registry  *metrics.Registry
+	collector *poolCollector
 
 	Builder squirrel.StatementBuilderType
 	Pool    PgxPool
@@ -55,16 +65,21 @@ type Postgres struct {
 
 func New(log *logger.Logger, cfg *Config, opts ...Option) (*Postgres, error) {
 	pg := &Postgres{
-		maxPoolSize:       defaultMaxPoolSize,
-		minPoolSize:       defaultMinPoolSize,
-		connAttempts:      defaultConnAttempts,
-		maxConnLifetime:   defaultConnLifetime,
-		maxConnIdleTime:   defaultIdleTime,
-		healthCheckPeriod: defaultHealthCheckPeriod,
-		connTimeout:       defaultConnTimeout,
-		Builder:           squirrel.StatementBuilderType{},
-		Log:               log,
-		Pool:              nil,
+		maxPoolSize:        defaultMaxPoolSize,
+		minPoolSize:        defaultMinPoolSize,
+		connAttempts:       defaultConnAttempts,
+		maxConnLifetime:    defaultConnLifetime,
+		maxConnIdleTime:    defaultIdleTime,
+		healthCheckPeriod:  defaultHealthCheckPeriod,
+		connTimeout:        defaultConnTimeout,
+		slowQueryThreshold: defaultSlowQueryThreshold,
+		database:           cfg.Database,
+		host:               net.JoinHostPort(cfg.Host, cfg.Port),
+		poolName:           defaultPoolName,
+		registry:           metrics.Default(),
+		Builder:            squirrel.StatementBuilderType{},
+		Log:                log,
+		Pool:               nil,
 	}
 
 	for _, opt := range opts {
@@ -80,7 +95,8 @@ func New(log *logger.Logger, cfg *Config, opts ...Option) (*Postgres, error) {
 		cfg.SSLMode,
 	)
 
-	log.Debug("connection url", slog.String("url", url))
+	// the url holds the password, so only the target is logged
+	log.Debug("connecting to postgres", slog.String("host", pg.host), slog.String("database", cfg.Database))
 
 	pg.Builder = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
@@ -94,7 +110,7 @@ func New(log *logger.Logger, cfg *Config, opts ...Option) (*Postgres, error) {
 	pgConfig.MaxConnLifetime = pg.maxConnLifetime     // Maximum connection lifetime
 	pgConfig.MaxConnIdleTime = pg.maxConnIdleTime     // Maximum idle time before connection is closed
 	pgConfig.HealthCheckPeriod = pg.healthCheckPeriod // Health check period
-	pgConfig.ConnConfig.Tracer = otelpgx.NewTracer()
+	pgConfig.ConnConfig.Tracer = newQueryTracer(pg)
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), pgConfig)
@@ -132,7 +148,14 @@ func New(log *logger.Logger, cfg *Config, opts ...Option) (*Postgres, error) {
 	return pg, pg.Pool.Ping(ctx)
 }
 
+// Ping checks the database is reachable, used by health checks
+func (p *Postgres) Ping(ctx context.Context) error {
+	return p.Pool.Ping(ctx)
+}
+
 func (p *Postgres) Close() {
+	p.UnregisterMetrics()
+
 	if p.Pool != nil {
 		p.Pool.Close()
 	}
@@ -0,0 +1,98 @@
+package postgresql
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports pgxpool statistics of a single Postgres instance
+type poolCollector struct {
+	pool PgxPool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	newConnsCount        *prometheus.Desc
+}
+
+func newPoolCollector(pool PgxPool, database, host, poolName string) *poolCollector {
+	labels := prometheus.Labels{"database": database, "host": host, "pool": poolName}
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("pgxpool_"+name, help, nil, labels)
+	}
+
+	return &poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Number of currently acquired connections in the pool"),
+		idleConns:            desc("idle_conns", "Number of currently idle connections in the pool"),
+		totalConns:           desc("total_conns", "Total number of resources currently in the pool"),
+		maxConns:             desc("max_conns", "Maximum size of the pool"),
+		constructingConns:    desc("constructing_conns", "Number of connections with construction in progress in the pool"),
+		acquireCount:         desc("acquire_count_total", "Cumulative count of successful acquires from the pool"),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Total duration of all successful acquires from the pool"),
+		canceledAcquireCount: desc("canceled_acquire_count_total", "Cumulative count of acquires from the pool that were canceled by a context"),
+		emptyAcquireCount:    desc("empty_acquire_count_total", "Cumulative count of successful acquires from the pool that waited for a resource"),
+		newConnsCount:        desc("new_conns_count_total", "Cumulative count of new connections opened"),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.constructingConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.canceledAcquireCount
+	ch <- c.emptyAcquireCount
+	ch <- c.newConnsCount
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.newConnsCount, prometheus.CounterValue, float64(stat.NewConnsCount()))
+}
+
+// RegisterMetrics registers pool statistics labelled by the database, host and pool name
+// in the registry set with MetricsRegistry. Close unregisters them.
+func (p *Postgres) RegisterMetrics() error {
+	if p.collector != nil {
+		return nil
+	}
+
+	collector := newPoolCollector(p.Pool, p.database, p.host, p.poolName)
+	if err := p.registry.Register(collector); err != nil {
+		return err
+	}
+
+	p.collector = collector
+
+	return nil
+}
+
+// UnregisterMetrics removes pool statistics from the registry
+func (p *Postgres) UnregisterMetrics() {
+	if p.collector == nil {
+		return
+	}
+
+	p.registry.Unregister(p.collector)
+	p.collector = nil
+}
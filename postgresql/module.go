@@ -6,31 +6,52 @@ import (
 	"time"
 
 	"github.com/TakeAway-Inc/platform/logger"
+	"github.com/TakeAway-Inc/platform/metrics"
 
 	"go.uber.org/fx"
 )
 
 const moduleName = "postgres"
 
+type moduleParams struct {
+	fx.In
+
+	Log *logger.Logger
+	Cfg *Config
+	// Registry is used for the pool metrics when provided, metrics.Default otherwise
+	Registry *metrics.Registry `optional:"true"`
+}
+
 func NewModule() fx.Option {
 	return fx.Module(
 		moduleName,
 
 		fx.Provide(
-			func(log *logger.Logger, cfg *Config) (*Postgres, error) {
-				return New(log, cfg, ConnAttempts(20),
+			func(p moduleParams) (*Postgres, error) {
+				opts := []Option{
+					ConnAttempts(20),
 					MinPoolSize(5),
 					MaxConnLifetime(time.Hour),
-					MaxConnIdleTime(30*time.Minute),
+					MaxConnIdleTime(30 * time.Minute),
 					ConnHealthCheckPeriod(time.Minute),
-				)
+				}
+
+				if p.Registry != nil {
+					opts = append(opts, MetricsRegistry(p.Registry))
+				}
+
+				return New(p.Log, p.Cfg, opts...)
 			},
 		),
 
 		fx.Invoke(func(
 			lc fx.Lifecycle,
 			p *Postgres,
-		) {
+		) error {
+			if err := p.RegisterMetrics(); err != nil {
+				return err
+			}
+
 			lc.Append(
 				fx.Hook{
 					OnStart: func(ctx context.Context) error {
@@ -42,6 +63,8 @@ func NewModule() fx.Option {
 					},
 				},
 			)
+
+			return nil
 		}),
 
 		fx.Decorate(func(log *logger.Logger) *logger.Logger {
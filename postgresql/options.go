@@ -1,6 +1,10 @@
 package postgresql
 
-import "time"
+import (
+	"time"
+
+	"github.com/TakeAway-Inc/platform/metrics"
+)
 
 type Option func(*Postgres)
 
@@ -45,3 +49,23 @@ func ConnHealthCheckPeriod(timeout time.Duration) Option {
 		c.healthCheckPeriod = timeout
 	}
 }
+
+// SlowQueryThreshold sets duration after which queries are logged as slow, 0 disables the log
+func SlowQueryThreshold(threshold time.Duration) Option {
+	return func(c *Postgres) {
+		c.slowQueryThreshold = threshold
+	}
+}
+
+func MetricsRegistry(reg *metrics.Registry) Option {
+	return func(c *Postgres) {
+		c.registry = reg
+	}
+}
+
+// PoolName distinguishes pool metrics of several instances connected to the same database, "default" by default
+func PoolName(name string) Option {
+	return func(c *Postgres) {
+		c.poolName = name
+	}
+}
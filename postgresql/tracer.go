@@ -0,0 +1,143 @@
+package postgresql
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/logger"
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
+)
+
+const unknownStatement = "other"
+
+type queryNameKey struct{}
+
+// WithQueryName labels queries made with the context in metrics and slow query logs,
+// otherwise the statement name is derived from the SQL, e.g. "select users"
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at        time.Time
+	sql       string
+	statement string
+}
+
+// queryTracer records query metrics and logs slow queries.
+// Batch, copy, prepare and connect tracing is left to the embedded otelpgx tracer.
+type queryTracer struct {
+	*otelpgx.Tracer
+
+	log                *logger.Logger
+	metrics            *metrics.PostgresMetrics
+	database           string
+	slowQueryThreshold time.Duration
+}
+
+func newQueryTracer(p *Postgres) *queryTracer {
+	return &queryTracer{
+		Tracer:             otelpgx.NewTracer(),
+		log:                p.Log,
+		metrics:            p.registry.PostgresMetrics(),
+		database:           p.database,
+		slowQueryThreshold: p.slowQueryThreshold,
+	}
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx = t.Tracer.TraceQueryStart(ctx, conn, data)
+
+	statement, ok := ctx.Value(queryNameKey{}).(string)
+	if !ok {
+		statement = statementName(data.SQL)
+	}
+
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{
+		at:        time.Now(),
+		sql:       data.SQL,
+		statement: statement,
+	})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.Tracer.TraceQueryEnd(ctx, conn, data)
+
+	start, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(start.at)
+
+	t.metrics.QueryDuration.WithLabelValues(t.database, start.statement).Observe(duration.Seconds())
+
+	if data.Err != nil {
+		t.metrics.QueryErrors.WithLabelValues(t.database, start.statement).Inc()
+	}
+
+	if t.slowQueryThreshold > 0 && duration >= t.slowQueryThreshold {
+		t.log.WarnContext(ctx, "slow query",
+			slog.String("statement", start.statement),
+			slog.String("sql", FormatQuery(start.sql)),
+			slog.Duration("duration", duration),
+		)
+	}
+}
+
+// statementName normalises SQL into "<command> <table>" to keep metric cardinality bounded
+func statementName(sql string) string {
+	fields := strings.Fields(strings.ToLower(sql))
+	if len(fields) == 0 {
+		return unknownStatement
+	}
+
+	command := fields[0]
+
+	var tableKeyword string
+
+	switch command {
+	case "select", "delete":
+		tableKeyword = "from"
+	case "insert":
+		tableKeyword = "into"
+	case "update":
+		if len(fields) > 1 {
+			return withTable(command, fields[1])
+		}
+
+		return command
+	case "with", "begin", "commit", "rollback", "create", "drop", "alter", "truncate", "copy":
+		return command
+	default:
+		return unknownStatement
+	}
+
+	for i, field := range fields[:len(fields)-1] {
+		if field == tableKeyword {
+			return withTable(command, fields[i+1])
+		}
+	}
+
+	return command
+}
+
+// withTable appends the table name, subqueries like "from (select ..." are skipped
+func withTable(command, table string) string {
+	if i := strings.IndexAny(table, "(,;"); i >= 0 {
+		table = table[:i]
+	}
+
+	if table = strings.Trim(table, `"`); table == "" {
+		return command
+	}
+
+	return command + " " + table
+}
@@ -0,0 +1,40 @@
+package postgresql
+
+import "testing"
+
+func TestStatementName(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{name: "empty", sql: "", want: unknownStatement},
+		{name: "blank", sql: " \n\t", want: unknownStatement},
+		{name: "select", sql: "SELECT id, name FROM users WHERE id = $1", want: "select users"},
+		{name: "select with schema", sql: "select * from public.orders", want: "select public.orders"},
+		{name: "select quoted table", sql: `SELECT * FROM "Users"`, want: "select users"},
+		{name: "select without table", sql: "SELECT 1", want: "select"},
+		{name: "select from subquery", sql: "SELECT count(*) FROM (SELECT id FROM users) t", want: "select"},
+		{name: "select trailing semicolon", sql: "select * from users;", want: "select users"},
+		{name: "select comma join", sql: "select * from users,orders", want: "select users"},
+		{name: "multiline", sql: "SELECT id\n\tFROM\n\t\trestaurants\n", want: "select restaurants"},
+		{name: "from is last word", sql: "select from", want: "select"},
+		{name: "insert", sql: "INSERT INTO orders (id, total) VALUES ($1, $2)", want: "insert orders"},
+		{name: "insert column list attached", sql: "insert into orders(id) values ($1)", want: "insert orders"},
+		{name: "update", sql: "UPDATE users SET name = $1", want: "update users"},
+		{name: "update without table", sql: "update", want: "update"},
+		{name: "delete", sql: "DELETE FROM sessions WHERE expires_at < now()", want: "delete sessions"},
+		{name: "with", sql: "WITH recent AS (SELECT 1) SELECT * FROM recent", want: "with"},
+		{name: "transaction", sql: "BEGIN", want: "begin"},
+		{name: "ddl", sql: "CREATE TABLE users (id int)", want: "create"},
+		{name: "unknown command", sql: "VACUUM ANALYZE users", want: unknownStatement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statementName(tt.sql); got != tt.want {
+				t.Errorf("statementName(%q) = %q, want %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
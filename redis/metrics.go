@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// metricsHook records duration and errors of every command
+type metricsHook struct {
+	m    *metrics.RedisMetrics
+	addr string
+	db   string
+}
+
+func (h *metricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *metricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+
+		err := next(ctx, cmd)
+
+		h.m.CommandDuration.WithLabelValues(h.addr, h.db, cmd.Name()).Observe(time.Since(start).Seconds())
+
+		if isError(err) {
+			h.m.CommandErrors.WithLabelValues(h.addr, h.db, cmd.Name()).Inc()
+		}
+
+		return err
+	}
+}
+
+func (h *metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+
+		err := next(ctx, cmds)
+
+		h.m.CommandDuration.WithLabelValues(h.addr, h.db, "pipeline").Observe(time.Since(start).Seconds())
+
+		for _, cmd := range cmds {
+			if isError(cmd.Err()) {
+				h.m.CommandErrors.WithLabelValues(h.addr, h.db, cmd.Name()).Inc()
+			}
+		}
+
+		return err
+	}
+}
+
+// isError reports whether the command failed, missing key is not a failure
+func isError(err error) bool {
+	return err != nil && !errors.Is(err, redis.Nil)
+}
+
+// poolCollector exports connection pool statistics of the client
+type poolCollector struct {
+	client *redis.Client
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+func newPoolCollector(client *redis.Client, addr, db string) *poolCollector {
+	labels := prometheus.Labels{"addr": addr, "db": db}
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("redis_pool_"+name, help, nil, labels)
+	}
+
+	return &poolCollector{
+		client:     client,
+		hits:       desc("hits_total", "Number of times free connection was found in the pool"),
+		misses:     desc("misses_total", "Number of times free connection was not found in the pool"),
+		timeouts:   desc("timeouts_total", "Number of times a wait timeout occurred"),
+		totalConns: desc("total_conns", "Number of total connections in the pool"),
+		idleConns:  desc("idle_conns", "Number of idle connections in the pool"),
+		staleConns: desc("stale_conns_total", "Number of stale connections removed from the pool"),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -2,12 +2,16 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/TakeAway-Inc/platform/logger"
+	"github.com/TakeAway-Inc/platform/metrics"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
@@ -19,46 +23,107 @@ type Config struct {
 }
 
 type Client struct {
-	log *logger.Logger
+	log      *logger.Logger
+	registry *metrics.Registry
+	metrics  *metrics.RedisMetrics
+
+	addr      string
+	db        string
+	collector *poolCollector
 
 	redisClient *redis.Client
 }
 
-func NewClient(log *logger.Logger, cfg *Config) (*Client, error) {
-	log = log.With(slog.String("module", "redis"))
+type Option func(*Client)
+
+// MetricsRegistry sets registry for the redis metrics, metrics.Default by default
+func MetricsRegistry(reg *metrics.Registry) Option {
+	return func(c *Client) {
+		c.registry = reg
+	}
+}
+
+func NewClient(log *logger.Logger, cfg *Config, opts ...Option) (*Client, error) {
+	c := &Client{
+		log:      log.With(slog.String("module", "redis")),
+		registry: metrics.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.addr = fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
+	c.db = strconv.Itoa(cfg.Database)
+	c.metrics = c.registry.RedisMetrics()
 
 	redisClient := redis.NewClient(&redis.Options{
-		Addr: fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Addr: c.addr,
 		DB:   cfg.Database,
 	})
 
 	if err := redisClient.Ping(context.Background()).Err(); err != nil {
+		_ = redisClient.Close()
 		return nil, err
 	}
 
 	if err := redisotel.InstrumentTracing(redisClient); err != nil {
+		_ = redisClient.Close()
 		return nil, err
 	}
 
-	return &Client{
-		log:         log,
-		redisClient: redisClient,
-	}, nil
+	redisClient.AddHook(&metricsHook{m: c.metrics, addr: c.addr, db: c.db})
+
+	collector := newPoolCollector(redisClient, c.addr, c.db)
+	if err := c.registry.Register(collector); err != nil {
+		// pool statistics of the same addr and db are already exported by another client
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			_ = redisClient.Close()
+			return nil, err
+		}
+
+		c.log.Warn("pool metrics are exported by another client", slog.String("addr", c.addr), slog.String("db", c.db))
+	} else {
+		c.collector = collector
+	}
+
+	c.redisClient = redisClient
+
+	return c, nil
 }
 
 func (c *Client) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
-	c.log.Info("set value into redis", slog.String("key", key), slog.Any("value", value))
+	// the value is not logged, it may be large or hold user data
+	c.log.InfoContext(ctx, "set value into redis", slog.String("key", key), slog.Duration("expiration", expiration))
 	return c.redisClient.Set(ctx, key, value, expiration).Err()
 }
 
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
-	return c.redisClient.Get(ctx, key).Result()
+	val, err := c.redisClient.Get(ctx, key).Result()
+
+	switch {
+	case err == nil:
+		c.metrics.CacheHits.WithLabelValues(c.addr, c.db).Inc()
+	case errors.Is(err, redis.Nil):
+		c.metrics.CacheMisses.WithLabelValues(c.addr, c.db).Inc()
+	}
+
+	return val, err
 }
 
 func (c *Client) Del(ctx context.Context, key string) error {
 	return c.redisClient.Del(ctx, key).Err()
 }
 
+// Ping checks redis is reachable, used by health checks
+func (c *Client) Ping(ctx context.Context) error {
+	return c.redisClient.Ping(ctx).Err()
+}
+
 func (c *Client) Close() error {
+	if c.collector != nil {
+		c.registry.Unregister(c.collector)
+	}
+
 	return c.redisClient.Close()
 }
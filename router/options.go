@@ -0,0 +1,46 @@
+package router
+
+import (
+	"github.com/TakeAway-Inc/platform/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Option func(*Router)
+
+// SkipPaths sets paths which are not written into the access log, "/status" and "/metrics" by default
+func SkipPaths(paths ...string) Option {
+	return func(r *Router) {
+		r.skipPaths = paths
+	}
+}
+
+// DurationBuckets sets buckets of the request duration histogram in seconds
+func DurationBuckets(buckets ...float64) Option {
+	return func(r *Router) {
+		r.metricsOpts.DurationBuckets = buckets
+	}
+}
+
+// SizeBuckets sets buckets of the request and response size histograms in bytes
+func SizeBuckets(buckets ...float64) Option {
+	return func(r *Router) {
+		r.metricsOpts.SizeBuckets = buckets
+	}
+}
+
+// MetricsRegistry sets registry for the HTTP metrics served on /metrics, metrics.Default by default
+func MetricsRegistry(reg *metrics.Registry) Option {
+	return func(r *Router) {
+		r.registry = reg
+	}
+}
+
+// LogLevelsEndpoint mounts GET and PUT /log/levels to change log levels at runtime.
+// The endpoint is off by default, middleware is run before it and must authorize the caller.
+func LogLevelsEndpoint(middleware ...gin.HandlerFunc) Option {
+	return func(r *Router) {
+		r.levelsEndpoint = true
+		r.levelsMiddleware = middleware
+	}
+}
@@ -1,9 +1,13 @@
 package router
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/TakeAway-Inc/platform/logger"
 	"github.com/TakeAway-Inc/platform/metrics"
@@ -11,27 +15,61 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+const (
+	requestIDHeader = "X-Request-ID"
+	unmatchedRoute  = "unmatched"
+)
+
+// defaultSkipPaths are not written into the access log
+var defaultSkipPaths = []string{"/status", "/metrics"}
+
 type Router struct {
-	r *gin.Engine
+	r   *gin.Engine
+	log *logger.Logger
+
+	skipPaths      []string
+	registry       *metrics.Registry
+	metricsOpts    metrics.HTTPMetricsOpts
+	metricsHandler http.Handler
+
+	levelsEndpoint   bool
+	levelsMiddleware []gin.HandlerFunc
 }
 
-func New(addr string, log *logger.Logger) *Router {
+func New(addr string, log *logger.Logger, opts ...Option) *Router {
 	if env := os.Getenv("APP_ENV"); env == "prod" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := &Router{r: gin.New()}
+	r := &Router{
+		r:         gin.New(),
+		log:       log,
+		skipPaths: defaultSkipPaths,
+		registry:  metrics.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.metricsHandler = r.registry.Handler()
 
 	r.r.Use(otelgin.Middleware(addr))
-	r.r.Use(logMiddleware(log))
-	r.r.Use(metricMiddleware())
+	r.r.Use(logMiddleware(log, r.skipPaths))
+	r.r.Use(metricMiddleware(r.registry.HTTPMetrics(&r.metricsOpts)))
 
 	r.r.GET("/status", r.status)
 	r.r.GET("/metrics", r.metrics)
+
+	if r.levelsEndpoint {
+		levels := r.r.Group("/log/levels", r.levelsMiddleware...)
+		levels.GET("", r.logLevels)
+		levels.PUT("", r.setLogLevel)
+	}
+
 	r.r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
 	return r
@@ -48,7 +86,7 @@ func (r *Router) Router() *gin.Engine {
 // @Success 200
 // @Router /metrics [get]
 func (r *Router) metrics(ctx *gin.Context) {
-	promhttp.Handler().ServeHTTP(ctx.Writer, ctx.Request)
+	r.metricsHandler.ServeHTTP(ctx.Writer, ctx.Request)
 }
 
 // @Summary Status Check
@@ -61,21 +99,154 @@ func (r *Router) status(ctx *gin.Context) {
 	ctx.String(http.StatusOK, "ok\n")
 }
 
-func logMiddleware(log *logger.Logger) gin.HandlerFunc {
+type setLogLevelRequest struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+}
+
+// @Summary Log Levels
+// @Description Default log level and per-component overrides
+// @Tags Status
+// @Produce application/json
+// @Success 200
+// @Router /log/levels [get]
+func (r *Router) logLevels(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, r.log.Levels().Levels())
+}
+
+// @Summary Set Log Level
+// @Description Changing log level of a component, empty component changes the default level, empty level resets the override
+// @Tags Status
+// @Accept application/json
+// @Produce application/json
+// @Success 200
+// @Failure 400
+// @Router /log/levels [put]
+func (r *Router) setLogLevel(ctx *gin.Context) {
+	var req setLogLevelRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	registry := r.log.Levels()
+
+	// unknown components are rejected, otherwise any name would stay in the registry
+	if req.Component != "" && !registry.Known(req.Component) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown component " + strconv.Quote(req.Component)})
+		return
+	}
+
+	if req.Level == "" {
+		registry.ResetLevel(req.Component)
+		ctx.JSON(http.StatusOK, registry.Levels())
+		return
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	registry.SetLevel(req.Component, level)
+
+	r.log.Info("log level changed", slog.String("target", req.Component), slog.String("level", level.String()))
+
+	ctx.JSON(http.StatusOK, registry.Levels())
+}
+
+// logMiddleware writes the access log after the request is handled,
+// the level is derived from the response status class
+func logMiddleware(log *logger.Logger, skipPaths []string) gin.HandlerFunc {
+	log = log.With(slog.String("component", "http server"))
+
+	skip := make(map[string]struct{}, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = struct{}{}
+	}
+
 	return func(c *gin.Context) {
-		log.Info("got http request", slog.String("method", c.Request.Method), slog.String("path", c.Request.URL.Path))
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		c.Header(requestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.ContextWithAttrs(c.Request.Context(), slog.String("request_id", requestID)))
+
+		start := time.Now()
 
 		c.Next()
+
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			return
+		}
+
+		status := c.Writer.Status()
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("size", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+
+		if err := c.Errors.Last(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		log.LogContext(c.Request.Context(), statusLevel(status), "http request", attrs...)
 	}
 }
 
-func metricMiddleware() gin.HandlerFunc {
+func statusLevel(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// metricMiddleware collects metrics labelled by the route template
+// instead of the raw path to keep cardinality bounded
+func metricMiddleware(m *metrics.HTTPMetrics) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		metrics.HTTPRequestsCount.With(map[string]string{
-			"uri":    c.Request.URL.Path,
-			"method": c.Request.Method,
-		}).Inc()
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		method := c.Request.Method
+
+		inFlight := m.InFlight.WithLabelValues(route, method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
 
 		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.RequestsTotal.WithLabelValues(route, method, status).Inc()
+		m.RequestDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+		m.RequestSize.WithLabelValues(route, method, status).Observe(float64(max(c.Request.ContentLength, 0)))
+		m.ResponseSize.WithLabelValues(route, method, status).Observe(float64(max(c.Writer.Size(), 0)))
 	}
 }
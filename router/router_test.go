@@ -0,0 +1,181 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TakeAway-Inc/platform/logger"
+	"github.com/TakeAway-Inc/platform/metrics"
+	"github.com/TakeAway-Inc/platform/tracing/tracingtest"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func newTestRouter(t *testing.T, opts ...Option) *Router {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	return New("orders", logger.New(), append([]Option{MetricsRegistry(metrics.NewRegistry())}, opts...)...)
+}
+
+func serve(r *Router, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.Router().ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRouterSpans(t *testing.T) {
+	spans := tracingtest.New(t)
+	r := newTestRouter(t)
+
+	r.Router().GET("/orders/:id", func(c *gin.Context) {
+		_, span := otel.Tracer("orders").Start(c.Request.Context(), "load order")
+		span.End()
+
+		c.String(http.StatusOK, "ok")
+	})
+
+	r.Router().GET("/fail", func(c *gin.Context) {
+		c.String(http.StatusInternalServerError, "fail")
+	})
+
+	t.Run("handler span is a child of the request span", func(t *testing.T) {
+		spans.Reset()
+
+		serve(r, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+
+		spans.AssertRoot("/orders/:id")
+		spans.AssertChildOf("load order", "/orders/:id")
+		spans.AssertAttr("/orders/:id", attribute.String("http.route", "/orders/:id"))
+		spans.AssertStatus("/orders/:id", codes.Unset)
+	})
+
+	t.Run("request joins the trace of the caller", func(t *testing.T) {
+		spans.Reset()
+
+		caller, parent := spans.Tracer("caller").Start(context.Background(), "call orders")
+
+		req := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+		otel.GetTextMapPropagator().Inject(caller, propagation.HeaderCarrier(req.Header))
+		parent.End()
+
+		serve(r, req)
+
+		spans.AssertChildOf("/orders/:id", "call orders")
+	})
+
+	t.Run("server error marks the span", func(t *testing.T) {
+		spans.Reset()
+
+		serve(r, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		spans.AssertStatus("/fail", codes.Error)
+		spans.AssertAttr("/fail", attribute.Int("http.status_code", http.StatusInternalServerError))
+	})
+}
+
+func TestLogLevelsEndpoint(t *testing.T) {
+	log, _ := logger.NewTest(t)
+	log.Levels().SetLevel("", slog.LevelInfo)
+	log.With(slog.String("component", "postgres"))
+
+	authorized := func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "Bearer admin" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	r := New("orders", log, MetricsRegistry(metrics.NewRegistry()), LogLevelsEndpoint(authorized))
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		auth   bool
+		status int
+		levels map[string]string
+	}{
+		{name: "unauthorized", method: http.MethodGet, status: http.StatusUnauthorized},
+		{name: "get", method: http.MethodGet, auth: true, status: http.StatusOK, levels: map[string]string{"default": "INFO"}},
+		{
+			name:   "set component",
+			method: http.MethodPut,
+			body:   `{"component":"postgres","level":"warn"}`,
+			auth:   true,
+			status: http.StatusOK,
+			levels: map[string]string{"default": "INFO", "postgres": "WARN"},
+		},
+		{name: "unknown component", method: http.MethodPut, body: `{"component":"postgress","level":"debug"}`, auth: true, status: http.StatusBadRequest},
+		{name: "invalid level", method: http.MethodPut, body: `{"component":"postgres","level":"loud"}`, auth: true, status: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPut, body: `{`, auth: true, status: http.StatusBadRequest},
+		{
+			name:   "set default",
+			method: http.MethodPut,
+			body:   `{"level":"error"}`,
+			auth:   true,
+			status: http.StatusOK,
+			levels: map[string]string{"default": "ERROR", "postgres": "WARN"},
+		},
+		{
+			name:   "reset component",
+			method: http.MethodPut,
+			body:   `{"component":"postgres"}`,
+			auth:   true,
+			status: http.StatusOK,
+			levels: map[string]string{"default": "ERROR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/log/levels", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			if tt.auth {
+				req.Header.Set("Authorization", "Bearer admin")
+			}
+
+			resp := serve(r, req)
+			if resp.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body %s", resp.Code, tt.status, resp.Body)
+			}
+
+			if tt.levels == nil {
+				return
+			}
+
+			var levels map[string]string
+			if err := json.Unmarshal(resp.Body.Bytes(), &levels); err != nil {
+				t.Fatalf("decode levels: %v", err)
+			}
+
+			if !maps.Equal(levels, tt.levels) {
+				t.Errorf("levels = %v, want %v", levels, tt.levels)
+			}
+		})
+	}
+}
+
+func TestLogLevelsEndpointDisabled(t *testing.T) {
+	r := newTestRouter(t)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		if resp := serve(r, httptest.NewRequest(method, "/log/levels", nil)); resp.Code != http.StatusNotFound {
+			t.Errorf("%s /log/levels = %d, want %d", method, resp.Code, http.StatusNotFound)
+		}
+	}
+}
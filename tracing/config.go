@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const defaultSampleRatio = 1
+
+// Config configures the tracer provider
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Attributes are added to the resource of every span
+	Attributes []attribute.KeyValue
+
+	// Exporter is taken from environment when nil, see ExporterConfigFromEnv
+	Exporter *ExporterConfig
+
+	// SampleRatio is the share of sampled root spans, child spans follow the parent decision.
+	// Every span is sampled when nil, zero means no span is sampled.
+	SampleRatio *float64
+	// IgnoreParent samples child spans by rules and SampleRatio as well, ignoring the parent decision
+	IgnoreParent bool
+	// SamplingRules are checked before SampleRatio, DefaultSamplingRules when nil
+	SamplingRules []SamplingRule
+
+	// Propagators are names of the context propagation formats, DefaultPropagators when empty
+	Propagators []string
+}
+
+// Ratio returns pointer to the ratio for Config.SampleRatio
+func Ratio(ratio float64) *float64 {
+	return &ratio
+}
+
+// ConfigFromEnv builds config of the service from environment: SERVICE_VERSION, APP_ENV,
+// OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG, OTEL_PROPAGATORS and the exporter variables
+func ConfigFromEnv(serviceName string) *Config {
+	cfg := &Config{
+		ServiceName:    serviceName,
+		ServiceVersion: os.Getenv("SERVICE_VERSION"),
+		Environment:    os.Getenv("APP_ENV"),
+		Exporter:       ExporterConfigFromEnv(),
+		Propagators:    PropagatorsFromEnv(),
+	}
+
+	cfg.SampleRatio, cfg.IgnoreParent = samplerFromEnv()
+
+	return cfg
+}
+
+// samplerFromEnv reads the standard OTEL_TRACES_SAMPLER values,
+// the ratio of traceidratio samplers is taken from OTEL_TRACES_SAMPLER_ARG
+func samplerFromEnv() (ratio *float64, ignoreParent bool) {
+	sampler := strings.ToLower(os.Getenv("OTEL_TRACES_SAMPLER"))
+
+	switch sampler {
+	case "always_on":
+		return Ratio(1), true
+	case "always_off":
+		return Ratio(0), true
+	case "parentbased_always_on":
+		return Ratio(1), false
+	case "parentbased_always_off":
+		return Ratio(0), false
+	}
+
+	ratio = Ratio(defaultSampleRatio)
+
+	if val := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); val != "" {
+		if r, err := strconv.ParseFloat(val, 64); err == nil {
+			ratio = Ratio(r)
+		}
+	}
+
+	return ratio, sampler == "traceidratio"
+}
@@ -1,15 +1,153 @@
 package tracing
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 const defaultEndpoint = "http://localhost:14268/api/traces"
 
+// Exporter types
+const (
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterJaeger   = "jaeger"
+	ExporterStdout   = "stdout"
+	ExporterNone     = "none"
+)
+
+// ExporterConfig selects and configures the span exporter.
+// Empty fields of OTLP exporters are taken from the standard OTEL_EXPORTER_OTLP_* variables.
+type ExporterConfig struct {
+	Type string
+	// Endpoint is host:port or a URL, e.g. http://collector:4318
+	Endpoint string
+	Insecure bool
+	Headers  map[string]string
+	Timeout  time.Duration
+}
+
+// ExporterConfigFromEnv builds config from OTEL_TRACES_EXPORTER and OTEL_EXPORTER_OTLP_* variables.
+// OTLP is used when an OTLP endpoint is set, Jaeger is used otherwise to stay compatible.
+func ExporterConfigFromEnv() *ExporterConfig {
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "otlp":
+		return &ExporterConfig{Type: otlpTypeFromEnv()}
+	case "console", "stdout":
+		return &ExporterConfig{Type: ExporterStdout}
+	case "none":
+		return &ExporterConfig{Type: ExporterNone}
+	case "jaeger":
+		return &ExporterConfig{Type: ExporterJaeger}
+	}
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		return &ExporterConfig{Type: otlpTypeFromEnv()}
+	}
+
+	return &ExporterConfig{Type: ExporterJaeger}
+}
+
+// otlpTypeFromEnv picks the OTLP transport, http/protobuf is the default protocol of the spec
+func otlpTypeFromEnv() string {
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+
+	if protocol == "grpc" {
+		return ExporterOTLPGRPC
+	}
+
+	return ExporterOTLPHTTP
+}
+
+// NewExporter creates exporter of the configured type
+func NewExporter(ctx context.Context, cfg *ExporterConfig) (trace.SpanExporter, error) {
+	switch cfg.Type {
+	case ExporterOTLPGRPC:
+		return NewOTLPGRPCExporter(ctx, cfg)
+	case ExporterOTLPHTTP:
+		return NewOTLPHTTPExporter(ctx, cfg)
+	case ExporterStdout:
+		return NewStdoutExporter()
+	case ExporterNone:
+		return tracetest.NewNoopExporter(), nil
+	case ExporterJaeger, "":
+		return NewJaegerExporter()
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Type)
+	}
+}
+
+// NewOTLPGRPCExporter creates OTLP exporter sending spans over gRPC
+func NewOTLPGRPCExporter(ctx context.Context, cfg *ExporterConfig) (trace.SpanExporter, error) {
+	var opts []otlptracegrpc.Option
+
+	if strings.Contains(cfg.Endpoint, "://") {
+		opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+	} else if cfg.Endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+	}
+
+	if cfg.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+	}
+
+	if cfg.Timeout > 0 {
+		opts = append(opts, otlptracegrpc.WithTimeout(cfg.Timeout))
+	}
+
+	return otlptracegrpc.New(ctx, opts...)
+}
+
+// NewOTLPHTTPExporter creates OTLP exporter sending spans over HTTP with protobuf encoding
+func NewOTLPHTTPExporter(ctx context.Context, cfg *ExporterConfig) (trace.SpanExporter, error) {
+	var opts []otlptracehttp.Option
+
+	if strings.Contains(cfg.Endpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	} else if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+
+	if cfg.Timeout > 0 {
+		opts = append(opts, otlptracehttp.WithTimeout(cfg.Timeout))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
+
+// NewStdoutExporter creates exporter printing spans to stdout, used for local development
+func NewStdoutExporter() (trace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithPrettyPrint())
+}
+
 // NewJaegerExporter creates new jaeger exporter
+//
+// Deprecated: the Jaeger exporter is no longer maintained upstream, Jaeger accepts OTLP, use NewOTLPGRPCExporter or NewOTLPHTTPExporter.
 func NewJaegerExporter() (trace.SpanExporter, error) {
 	var endpoint string
 
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+const moduleName = "tracing"
+
+type moduleParams struct {
+	fx.In
+
+	// Cfg is built from environment when not provided, service name is taken from SERVICE_NAME
+	Cfg *Config `optional:"true"`
+}
+
+func NewModule() fx.Option {
+	return fx.Module(
+		moduleName,
+
+		fx.Provide(
+			func(p moduleParams) (*sdktrace.TracerProvider, propagation.TextMapPropagator, error) {
+				cfg := p.Cfg
+				if cfg == nil {
+					cfg = ConfigFromEnv(os.Getenv("SERVICE_NAME"))
+				}
+
+				propagator, err := NewPropagator(cfg.Propagators...)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				traceProvider, err := NewProvider(context.Background(), cfg)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				return traceProvider, propagator, nil
+			},
+			func(tp *sdktrace.TracerProvider) trace.TracerProvider {
+				return tp
+			},
+			func(tp trace.TracerProvider) trace.Tracer {
+				return tp.Tracer(tracerName)
+			},
+		),
+
+		fx.Invoke(func(
+			lc fx.Lifecycle,
+			tp *sdktrace.TracerProvider,
+			propagator propagation.TextMapPropagator,
+		) {
+			otel.SetTracerProvider(tp)
+			otel.SetTextMapPropagator(propagator)
+
+			lc.Append(
+				fx.Hook{
+					OnStop: func(ctx context.Context) error {
+						// shutdown exports buffered spans, giving up at the stop deadline
+						return tp.Shutdown(ctx)
+					},
+				},
+			)
+		}),
+	)
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Propagator names, same as accepted by OTEL_PROPAGATORS
+const (
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"
+	PropagatorB3Multi      = "b3multi"
+)
+
+// DefaultPropagators are W3C trace context and baggage
+var DefaultPropagators = []string{PropagatorTraceContext, PropagatorBaggage}
+
+// PropagatorsFromEnv returns propagator names from OTEL_PROPAGATORS, DefaultPropagators when unset
+func PropagatorsFromEnv() []string {
+	val := os.Getenv("OTEL_PROPAGATORS")
+	if val == "" {
+		return DefaultPropagators
+	}
+
+	var names []string
+
+	for _, name := range strings.Split(val, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+
+	return names
+}
+
+// NewPropagator creates composite propagator of the named formats, DefaultPropagators when none given.
+// When several formats are extracted the last one wins.
+func NewPropagator(names ...string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = DefaultPropagators
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+
+	for _, name := range names {
+		switch name {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case PropagatorB3Multi:
+			propagators = append(propagators, b3.New())
+		case "none":
+		default:
+			return nil, fmt.Errorf("unknown propagator %q", name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
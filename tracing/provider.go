@@ -1,6 +1,9 @@
 package tracing
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
@@ -23,3 +26,69 @@ func NewTraceProvider(exporter trace.SpanExporter, serviceName string) (*trace.T
 		trace.WithResource(res),
 	), nil
 }
+
+// NewProvider creates tracer provider with the configured exporter, sampler and resource.
+// Provider's Shutdown must be called on exit to flush buffered spans.
+func NewProvider(ctx context.Context, cfg *Config) (*trace.TracerProvider, error) {
+	exporterCfg := cfg.Exporter
+	if exporterCfg == nil {
+		exporterCfg = ExporterConfigFromEnv()
+	}
+
+	exporter, err := NewExporter(ctx, exporterCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := cfg.SamplingRules
+	if rules == nil {
+		rules = DefaultSamplingRules
+	}
+
+	ratio := float64(defaultSampleRatio)
+	if cfg.SampleRatio != nil {
+		ratio = *cfg.SampleRatio
+	}
+
+	sampler := NewSampler(ratio, rules)
+	if cfg.IgnoreParent {
+		sampler = NewRuleSampler(rules, ratioSampler(ratio))
+	}
+
+	return trace.NewTracerProvider(
+		trace.WithBatcher(exporter),
+		trace.WithResource(res),
+		trace.WithSampler(sampler),
+	), nil
+}
+
+// newResource describes the service: name, version, environment, host and OTEL_RESOURCE_ATTRIBUTES
+func newResource(ctx context.Context, cfg *Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.ServiceName)}
+
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(cfg.Environment))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithHost(),
+		resource.WithTelemetrySDK(),
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(append(attrs, cfg.Attributes...)...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.Merge(resource.Default(), res)
+}
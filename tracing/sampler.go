@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// routeKeys are span attributes holding the request path, set by HTTP and gin instrumentation
+var routeKeys = []attribute.Key{
+	semconv.HTTPRouteKey,
+	semconv.URLPathKey,
+	"http.target",
+}
+
+// DefaultSamplingRules drop spans of health checks and metric scrapes
+var DefaultSamplingRules = []SamplingRule{
+	{Route: "/status", Ratio: 0},
+	{Route: "/metrics", Ratio: 0},
+}
+
+// SamplingRule samples matching root spans with its own ratio, 0 drops them.
+// A rule matches when every non-empty field matches.
+type SamplingRule struct {
+	// SpanName matches the span name exactly
+	SpanName string
+	// Route matches http.route, url.path or http.target attribute exactly
+	Route string
+	Ratio float64
+}
+
+func (r SamplingRule) match(p sdktrace.SamplingParameters) bool {
+	if r.SpanName == "" && r.Route == "" {
+		return false
+	}
+
+	if r.SpanName != "" && r.SpanName != p.Name {
+		return false
+	}
+
+	if r.Route != "" && !hasRoute(p.Attributes, r.Route) {
+		return false
+	}
+
+	return true
+}
+
+func hasRoute(attrs []attribute.KeyValue, route string) bool {
+	for _, attr := range attrs {
+		for _, key := range routeKeys {
+			if attr.Key == key && attr.Value.AsString() == route {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type ruleSampler struct {
+	rules    []SamplingRule
+	samplers []sdktrace.Sampler
+	fallback sdktrace.Sampler
+}
+
+// NewRuleSampler samples spans with the first matching rule, fallback is used when no rule matches
+func NewRuleSampler(rules []SamplingRule, fallback sdktrace.Sampler) sdktrace.Sampler {
+	s := &ruleSampler{
+		rules:    rules,
+		samplers: make([]sdktrace.Sampler, len(rules)),
+		fallback: fallback,
+	}
+
+	for i, rule := range rules {
+		s.samplers[i] = ratioSampler(rule.Ratio)
+	}
+
+	return s
+}
+
+func (s *ruleSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for i, rule := range s.rules {
+		if rule.match(p) {
+			return s.samplers[i].ShouldSample(p)
+		}
+	}
+
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *ruleSampler) Description() string {
+	parts := make([]string, len(s.rules))
+	for i, rule := range s.rules {
+		parts[i] = fmt.Sprintf("{name=%q,route=%q,ratio=%g}", rule.SpanName, rule.Route, rule.Ratio)
+	}
+
+	return fmt.Sprintf("RuleSampler{rules=[%s],fallback=%s}", strings.Join(parts, ","), s.fallback.Description())
+}
+
+// NewSampler creates parent-based sampler, root spans are sampled by rules and then by ratio
+func NewSampler(ratio float64, rules []SamplingRule) sdktrace.Sampler {
+	root := ratioSampler(ratio)
+	if len(rules) > 0 {
+		root = NewRuleSampler(rules, root)
+	}
+
+	return sdktrace.ParentBased(root)
+}
+
+func ratioSampler(ratio float64) sdktrace.Sampler {
+	switch {
+	case ratio >= 1:
+		return sdktrace.AlwaysSample()
+	case ratio <= 0:
+		return sdktrace.NeverSample()
+	default:
+		return sdktrace.TraceIDRatioBased(ratio)
+	}
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span wraps trace.Span, its End records the error of the operation
+type Span struct {
+	trace.Span
+}
+
+// Start starts span with the global provider, the usual way to instrument application code:
+//
+//	func (s *Service) CreateOrder(ctx context.Context) (err error) {
+//		ctx, span := tracing.Start(ctx, "create order")
+//		defer span.End(&err)
+//		...
+//	}
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, *Span) {
+	ctx, span := otel.GetTracerProvider().Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, &Span{Span: span}
+}
+
+// End records the error and sets error status when err points to non-nil error, then ends the span.
+// err is a pointer so a deferred call sees the value returned by the function.
+func (s *Span) End(err *error, opts ...trace.SpanEndOption) {
+	if err != nil && *err != nil {
+		s.RecordError(*err)
+		s.SetStatus(codes.Error, (*err).Error())
+	}
+
+	s.Span.End(opts...)
+}
+
+// Run calls fn inside a span, the error of fn is recorded.
+// Panics are recorded as errors and propagated.
+func Run(ctx context.Context, name string, fn func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
+	_, err := Call(ctx, name, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, attrs...)
+
+	return err
+}
+
+// Call is Run for functions returning a value
+func Call[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error), attrs ...attribute.KeyValue) (res T, err error) {
+	ctx, span := Start(ctx, name, attrs...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("panic: %v", r))
+			span.AddEvent("panic", trace.WithAttributes(attribute.String("panic.value", fmt.Sprint(r))))
+			span.Span.End()
+
+			panic(r)
+		}
+
+		span.End(&err)
+	}()
+
+	return fn(ctx)
+}
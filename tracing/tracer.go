@@ -1,22 +1,37 @@
 package tracing
 
 import (
+	"context"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 )
 
-func New(serviceName string) (trace.Tracer, error) {
-	jaegerExporter, err := NewJaegerExporter()
+const tracerName = "main service"
+
+// ShutdownFunc flushes buffered spans and stops the exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// New creates tracer configured by environment, see ConfigFromEnv.
+// Returned shutdown must be called on exit, otherwise buffered spans are lost.
+func New(serviceName string) (trace.Tracer, ShutdownFunc, error) {
+	return NewWithConfig(context.Background(), ConfigFromEnv(serviceName))
+}
+
+// NewWithConfig creates tracer provider from config and sets it as global together with the propagator
+func NewWithConfig(ctx context.Context, cfg *Config) (trace.Tracer, ShutdownFunc, error) {
+	propagator, err := NewPropagator(cfg.Propagators...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	traceProvider, err := NewTraceProvider(jaegerExporter, serviceName)
+	traceProvider, err := NewProvider(ctx, cfg)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	otel.SetTracerProvider(traceProvider)
+	otel.SetTextMapPropagator(propagator)
 
-	return traceProvider.Tracer("main service"), nil
+	return traceProvider.Tracer(tracerName), traceProvider.Shutdown, nil
 }
@@ -0,0 +1,252 @@
+// Package tracingtest records spans in memory to verify instrumentation in tests
+package tracingtest
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestingT is the part of testing.TB used by the recorder
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+}
+
+// Recorder keeps every ended span in memory
+type Recorder struct {
+	t        TestingT
+	exporter *tracetest.InMemoryExporter
+	provider *sdktrace.TracerProvider
+}
+
+// New installs recording provider and W3C propagator as global ones, previous are restored on cleanup.
+// Spans are exported synchronously when they end and every span is sampled.
+//
+// Tracers obtained from the global provider before the first provider was ever set stick
+// to that provider, so instrumented components must be created after New.
+func New(t TestingT) *Recorder {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+	)
+
+	prevProvider := otel.GetTracerProvider()
+	prevPropagator := otel.GetTextMapPropagator()
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return &Recorder{
+		t:        t,
+		exporter: exporter,
+		provider: provider,
+	}
+}
+
+// Provider returns the recording provider, used to inject it instead of the global one
+func (r *Recorder) Provider() *sdktrace.TracerProvider {
+	return r.provider
+}
+
+// Tracer returns tracer of the recording provider
+func (r *Recorder) Tracer(name string) trace.Tracer {
+	return r.provider.Tracer(name)
+}
+
+// Spans returns ended spans in the order they ended
+func (r *Recorder) Spans() tracetest.SpanStubs {
+	return r.exporter.GetSpans()
+}
+
+// Reset removes all recorded spans
+func (r *Recorder) Reset() {
+	r.exporter.Reset()
+}
+
+// ByName returns spans with the name
+func (r *Recorder) ByName(name string) []tracetest.SpanStub {
+	var matched []tracetest.SpanStub
+
+	for _, span := range r.Spans() {
+		if span.Name == name {
+			matched = append(matched, span)
+		}
+	}
+
+	return matched
+}
+
+// Span returns the first span with the name
+func (r *Recorder) Span(name string) (tracetest.SpanStub, bool) {
+	spans := r.ByName(name)
+	if len(spans) == 0 {
+		return tracetest.SpanStub{}, false
+	}
+
+	return spans[0], true
+}
+
+// Children returns spans whose parent is the span
+func (r *Recorder) Children(parent tracetest.SpanStub) []tracetest.SpanStub {
+	var children []tracetest.SpanStub
+
+	for _, span := range r.Spans() {
+		if span.Parent.SpanID() == parent.SpanContext.SpanID() && span.Parent.TraceID() == parent.SpanContext.TraceID() {
+			children = append(children, span)
+		}
+	}
+
+	return children
+}
+
+// AssertSpan fails the test when no span with the name ended, the first matching span is returned
+func (r *Recorder) AssertSpan(name string) tracetest.SpanStub {
+	r.t.Helper()
+
+	span, ok := r.Span(name)
+	if !ok {
+		r.t.Errorf("span %q not recorded, recorded: %v", name, r.names())
+	}
+
+	return span
+}
+
+// AssertNoSpan fails the test when a span with the name ended
+func (r *Recorder) AssertNoSpan(name string) {
+	r.t.Helper()
+
+	if _, ok := r.Span(name); ok {
+		r.t.Errorf("span %q recorded", name)
+	}
+}
+
+// AssertChildOf fails the test when span child is not a direct child of span parent
+func (r *Recorder) AssertChildOf(child, parent string) {
+	r.t.Helper()
+
+	c, ok := r.Span(child)
+	if !ok {
+		r.t.Errorf("span %q not recorded, recorded: %v", child, r.names())
+		return
+	}
+
+	p, ok := r.Span(parent)
+	if !ok {
+		r.t.Errorf("span %q not recorded, recorded: %v", parent, r.names())
+		return
+	}
+
+	if c.Parent.SpanID() != p.SpanContext.SpanID() || c.SpanContext.TraceID() != p.SpanContext.TraceID() {
+		r.t.Errorf("span %q is not a child of %q: parent %s, want %s", child, parent, c.Parent.SpanID(), p.SpanContext.SpanID())
+	}
+}
+
+// AssertRoot fails the test when the span has a parent
+func (r *Recorder) AssertRoot(name string) {
+	r.t.Helper()
+
+	span, ok := r.Span(name)
+	if !ok {
+		r.t.Errorf("span %q not recorded, recorded: %v", name, r.names())
+		return
+	}
+
+	if span.Parent.IsValid() {
+		r.t.Errorf("span %q has parent %s", name, span.Parent.SpanID())
+	}
+}
+
+// AssertAttr fails the test when the span has no attribute with the value
+func (r *Recorder) AssertAttr(name string, want attribute.KeyValue) {
+	r.t.Helper()
+
+	span, ok := r.Span(name)
+	if !ok {
+		r.t.Errorf("span %q not recorded, recorded: %v", name, r.names())
+		return
+	}
+
+	got, ok := Attr(span, want.Key)
+	if !ok {
+		r.t.Errorf("span %q has no attribute %q", name, want.Key)
+		return
+	}
+
+	if got != want.Value {
+		r.t.Errorf("span %q attribute %q = %s, want %s", name, want.Key, got.Emit(), want.Value.Emit())
+	}
+}
+
+// AssertStatus fails the test when the span status code differs
+func (r *Recorder) AssertStatus(name string, code codes.Code) {
+	r.t.Helper()
+
+	span, ok := r.Span(name)
+	if !ok {
+		r.t.Errorf("span %q not recorded, recorded: %v", name, r.names())
+		return
+	}
+
+	if span.Status.Code != code {
+		r.t.Errorf("span %q status = %s %q, want %s", name, span.Status.Code, span.Status.Description, code)
+	}
+}
+
+// AssertEvent fails the test when the span has no event with the name
+func (r *Recorder) AssertEvent(name, event string) {
+	r.t.Helper()
+
+	span, ok := r.Span(name)
+	if !ok {
+		r.t.Errorf("span %q not recorded, recorded: %v", name, r.names())
+		return
+	}
+
+	for _, e := range span.Events {
+		if e.Name == event {
+			return
+		}
+	}
+
+	r.t.Errorf("span %q has no event %q", name, event)
+}
+
+func (r *Recorder) names() []string {
+	spans := r.Spans()
+
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name
+	}
+
+	return names
+}
+
+// Attr returns the value of the span attribute
+func Attr(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
@@ -0,0 +1,77 @@
+package tracingtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// fakeT records failures of the assertions instead of failing the test
+type fakeT struct {
+	*testing.T
+	errors []string
+}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorderAssertions(t *testing.T) {
+	ft := &fakeT{T: t}
+	rec := New(ft)
+
+	ctx, parent := rec.Tracer("test").Start(context.Background(), "parent")
+	_, child := rec.Tracer("test").Start(ctx, "child")
+	child.SetAttributes(attribute.String("db.system", "postgresql"))
+	child.AddEvent("retry")
+	child.SetStatus(codes.Error, "timeout")
+	child.End()
+	parent.End()
+
+	tests := []struct {
+		name   string
+		assert func()
+		fails  bool
+	}{
+		{name: "span", assert: func() { rec.AssertSpan("child") }},
+		{name: "missing span", assert: func() { rec.AssertSpan("other") }, fails: true},
+		{name: "no span", assert: func() { rec.AssertNoSpan("other") }},
+		{name: "unexpected span", assert: func() { rec.AssertNoSpan("child") }, fails: true},
+		{name: "child of", assert: func() { rec.AssertChildOf("child", "parent") }},
+		{name: "not child of", assert: func() { rec.AssertChildOf("parent", "child") }, fails: true},
+		{name: "root", assert: func() { rec.AssertRoot("parent") }},
+		{name: "not root", assert: func() { rec.AssertRoot("child") }, fails: true},
+		{name: "attr", assert: func() { rec.AssertAttr("child", attribute.String("db.system", "postgresql")) }},
+		{name: "attr value", assert: func() { rec.AssertAttr("child", attribute.String("db.system", "redis")) }, fails: true},
+		{name: "missing attr", assert: func() { rec.AssertAttr("parent", attribute.String("db.system", "postgresql")) }, fails: true},
+		{name: "status", assert: func() { rec.AssertStatus("child", codes.Error) }},
+		{name: "wrong status", assert: func() { rec.AssertStatus("parent", codes.Error) }, fails: true},
+		{name: "event", assert: func() { rec.AssertEvent("child", "retry") }},
+		{name: "missing event", assert: func() { rec.AssertEvent("parent", "retry") }, fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft.errors = nil
+
+			tt.assert()
+
+			if failed := len(ft.errors) > 0; failed != tt.fails {
+				t.Errorf("failed = %v, want %v, errors: %v", failed, tt.fails, ft.errors)
+			}
+		})
+	}
+
+	if children := rec.Children(rec.AssertSpan("parent")); len(children) != 1 || children[0].Name != "child" {
+		t.Errorf("children = %v, want [child]", children)
+	}
+
+	rec.Reset()
+
+	if spans := rec.Spans(); len(spans) != 0 {
+		t.Errorf("spans after reset = %d, want 0", len(spans))
+	}
+}